@@ -0,0 +1,213 @@
+package structviewer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Apply sets the fields of the given target, a pointer to a structure of the type of the configuration, to the
+// values of their environment variables in the given environment, such as Config.Environ or ProcessEnviron(). The
+// environment variables are named by the naming strategy of the Viewer, exactly as EnvsHandler exposes them, and
+// their values are decoded as envconfig does: '1m30s' for a time.Duration, 'a,b,c' for a slice or 'k1:v1,k2:v2' for a
+// map. When the naming strategy expands the collections, the elements of slices and maps can also be set one by one,
+// e.g. 'PREFIX_TAGS_1=b' or 'PREFIX_HEADERS_X-ENV=prod', the latter adding the entry if the map does not hold it yet.
+// Fields without environment variable are left unchanged.
+func (v *Viewer) Apply(environ map[string]string, target interface{}) error {
+	value := reflect.ValueOf(target)
+	t := reflect.TypeOf(v.original).Elem()
+
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Type() != t {
+		return fmt.Errorf("%w: target of type %T instead of *%s", ErrInvalidObjectType, target, t)
+	}
+
+	return v.applyStruct(environ, value.Elem(), v.prefix)
+}
+
+// applyStruct sets the fields of the given struct whose environment variables, prefixed with the given prefix, are
+// set in the given environment.
+func (v *Viewer) applyStruct(environ map[string]string, s reflect.Value, prefix string) error {
+	for _, field := range v.structFields(s.Type()) {
+		name := prefix + v.naming.FieldEnv(field.Field)
+		if !v.hasEnvs(environ, name) {
+			continue
+		}
+
+		if err := v.applyValue(environ, settableFieldByIndex(s, field.index), name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyValue sets the given settable value to the value of the environment variable with the given name, then sets
+// its fields or elements to the values of their own environment variables.
+func (v *Viewer) applyValue(environ map[string]string, value reflect.Value, name string) error {
+	if s, ok := environ[name]; ok {
+		if err := decodeInto(s, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	t := value.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	prefix := name + v.naming.Separator()
+	if formatterFor(t) != nil || !hasPrefixedEnvs(environ, prefix) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return v.applyStruct(environ, allocate(value), prefix)
+	case reflect.Map:
+		if v.naming.ExpandCollections() {
+			return v.applyMap(environ, allocate(value), prefix)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.naming.ExpandCollections() {
+			return v.applyElements(environ, allocate(value), prefix)
+		}
+	default:
+	}
+
+	return nil
+}
+
+// applyMap sets the entries of the given map to the values of their environment variables, prefixed with the given
+// prefix. Entries are added for the environment variables matching no entry, unless the entries are structs or
+// collections whose key cannot be told apart from their fields.
+func (v *Viewer) applyMap(environ map[string]string, m reflect.Value, prefix string) error {
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	known := map[string]bool{}
+
+	for _, key := range m.MapKeys() {
+		name := prefix + v.naming.MapKeyEnv(fmt.Sprintf("%v", key))
+		known[name] = true
+
+		if !v.hasEnvs(environ, name) {
+			continue
+		}
+
+		elem := reflect.New(m.Type().Elem()).Elem()
+		elem.Set(m.MapIndex(key))
+
+		if err := v.applyValue(environ, elem, name); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(key, elem)
+	}
+
+	elemType := m.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	switch elemType.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		if formatterFor(elemType) == nil {
+			return nil
+		}
+	default:
+	}
+
+	for name, s := range environ {
+		if known[name] || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		key, err := decodeValue(strings.TrimPrefix(name, prefix), m.Type().Key())
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		elem, err := decodeValue(s, m.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		m.SetMapIndex(key, elem)
+	}
+
+	return nil
+}
+
+// applyElements sets the elements of the given slice or array to the values of their environment variables, prefixed
+// with the given prefix. Slices are extended with the elements following their last one.
+func (v *Viewer) applyElements(environ map[string]string, s reflect.Value, prefix string) error {
+	for i := 0; i < s.Len() || s.Kind() == reflect.Slice; i++ {
+		name := prefix + v.naming.IndexEnv(i)
+		if !v.hasEnvs(environ, name) {
+			if i >= s.Len() {
+				return nil
+			}
+
+			continue
+		}
+
+		if i >= s.Len() {
+			s.Set(reflect.Append(s, reflect.New(s.Type().Elem()).Elem()))
+		}
+
+		if err := v.applyValue(environ, s.Index(i), name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasEnvs reports whether the given environment sets the environment variable with the given name, or one of the
+// environment variables of its fields or elements.
+func (v *Viewer) hasEnvs(environ map[string]string, name string) bool {
+	if _, ok := environ[name]; ok {
+		return true
+	}
+
+	return hasPrefixedEnvs(environ, name+v.naming.Separator())
+}
+
+// hasPrefixedEnvs reports whether the given environment sets an environment variable with the given prefix.
+func hasPrefixedEnvs(environ map[string]string, prefix string) bool {
+	for env := range environ {
+		if strings.HasPrefix(env, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// settableFieldByIndex returns the field of the given settable struct with the given index sequence, allocating the
+// nil embedded pointers.
+func settableFieldByIndex(s reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			s = allocate(s)
+		}
+
+		s = s.Field(x)
+	}
+
+	return s
+}
+
+// allocate dereferences the given settable value until it is not a pointer anymore, allocating the nil pointers.
+func allocate(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
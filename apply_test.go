@@ -0,0 +1,156 @@
+package structviewer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testApplyConfig struct {
+	ListenPort int                `json:"listen_port"`
+	Debug      bool               `json:"debug"`
+	Timeout    time.Duration      `json:"timeout"`
+	Tags       []string           `json:"tags"`
+	Ports      []int              `json:"ports"`
+	Headers    map[string]string  `json:"headers"`
+	Storage    *testApplyStorage  `json:"storage"`
+	Upstreams  []testApplyStorage `json:"upstreams"`
+}
+
+type testApplyStorage struct {
+	Addr    string `json:"addr"`
+	Retries uint   `json:"retries"`
+}
+
+func TestApply(t *testing.T) {
+	tcs := []struct {
+		testName     string
+		givenNaming  NamingStrategy
+		givenTarget  testApplyConfig
+		givenEnviron map[string]string
+		expected     testApplyConfig
+		expectedErr  string
+	}{
+		{
+			testName: "scalars",
+			givenEnviron: map[string]string{
+				"APP_LISTENPORT": "8080",
+				"APP_DEBUG":      "true",
+				"APP_TIMEOUT":    "1m30s",
+				"OTHER_DEBUG":    "false",
+			},
+			expected: testApplyConfig{ListenPort: 8080, Debug: true, Timeout: 90 * time.Second},
+		},
+		{
+			testName: "collections",
+			givenEnviron: map[string]string{
+				"APP_TAGS":    "a,b",
+				"APP_PORTS":   "80, 443",
+				"APP_HEADERS": "X-Env:prod,X-Region:eu",
+			},
+			expected: testApplyConfig{
+				Tags:    []string{"a", "b"},
+				Ports:   []int{80, 443},
+				Headers: map[string]string{"X-Env": "prod", "X-Region": "eu"},
+			},
+		},
+		{
+			testName:    "elements",
+			givenTarget: testApplyConfig{Tags: []string{"a"}, Headers: map[string]string{"X-Env": "dev"}},
+			givenEnviron: map[string]string{
+				"APP_TAGS_1":             "b",
+				"APP_HEADERS_X-ENV":      "prod",
+				"APP_HEADERS_REGION":     "eu",
+				"APP_UPSTREAMS_0_ADDR":   "eu:80",
+				"APP_STORAGE_ADDR":       "redis:6379",
+				"APP_STORAGE_RETRIES":    "3",
+				"APP_UPSTREAMS_1_ADDR":   "us:80",
+				"APP_UPSTREAMS_3_ADDR":   "ignored:80",
+				"APP_UPSTREAMS_1_UNKNOW": "ignored",
+			},
+			expected: testApplyConfig{
+				Tags:      []string{"a", "b"},
+				Headers:   map[string]string{"X-Env": "prod", "REGION": "eu"},
+				Storage:   &testApplyStorage{Addr: "redis:6379", Retries: 3},
+				Upstreams: []testApplyStorage{{Addr: "eu:80"}, {Addr: "us:80"}},
+			},
+		},
+		{
+			testName:     "envconfig naming",
+			givenNaming:  EnvconfigNaming,
+			givenEnviron: map[string]string{"APP_LISTENPORT": "8080", "APP_TAGS": "a,b", "APP_TAGS_0": "ignored"},
+			expected:     testApplyConfig{ListenPort: 8080, Tags: []string{"a", "b"}},
+		},
+		{
+			testName:     "invalid value",
+			givenEnviron: map[string]string{"APP_STORAGE_RETRIES": "-1"},
+			expectedErr:  "APP_STORAGE_RETRIES: ",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: testApplyConfig{}, Naming: tc.givenNaming}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			target := tc.givenTarget
+
+			err = viewer.Apply(tc.givenEnviron, &target)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+		})
+	}
+}
+
+func TestApplyRoundTrip(t *testing.T) {
+	config := testApplyConfig{
+		ListenPort: 8080,
+		Debug:      true,
+		Timeout:    time.Minute,
+		Tags:       []string{"a", "b"},
+		Ports:      []int{80, 443},
+		Headers:    map[string]string{"XENV": "prod"},
+		Storage:    &testApplyStorage{Addr: "redis:6379", Retries: 3},
+		Upstreams:  []testApplyStorage{{Addr: "eu:80", Retries: 1}, {Addr: "us:80", Retries: 2}},
+	}
+
+	for _, naming := range []NamingStrategy{DefaultNaming, EnvconfigNaming, ScreamingSnakeNaming, ViperNaming} {
+		viewer, err := New(&Config{Object: config, Naming: naming}, "APP_")
+		assert.NoError(t, err, "failed to instantiate viewer")
+
+		environ := map[string]string{}
+
+		for _, env := range viewer.ParseEnvs() {
+			name, value, _ := strings.Cut(env, "=")
+			environ[name] = value
+		}
+
+		var target testApplyConfig
+		if naming == EnvconfigNaming {
+			// Collections of structs cannot be set by a single environment variable.
+			delete(environ, "APP_UPSTREAMS")
+
+			target.Upstreams = config.Upstreams
+		}
+
+		assert.NoError(t, viewer.Apply(environ, &target))
+		assert.Equal(t, config, target, "the exposed environment variables must load the configuration back")
+	}
+}
+
+func TestApplyInvalidTarget(t *testing.T) {
+	viewer, err := New(&Config{Object: testApplyConfig{}}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	for _, target := range []interface{}{nil, testApplyConfig{}, (*testApplyConfig)(nil), &testApplyStorage{}} {
+		assert.True(t, errors.Is(viewer.Apply(nil, target), ErrInvalidObjectType))
+	}
+}
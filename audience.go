@@ -0,0 +1,115 @@
+package structviewer
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// Built-in audiences, from the least to the most privileged.
+const (
+	// PublicAudience is the audience of anonymous callers.
+	PublicAudience = "public"
+	// OperatorAudience is the audience of the operators running the service.
+	OperatorAudience = "operator"
+	// AdminAudience is the audience of the administrators of the service.
+	AdminAudience = "admin"
+)
+
+// DefaultAudiences are the audiences of the Viewer when none are configured.
+var DefaultAudiences = []string{PublicAudience, OperatorAudience, AdminAudience}
+
+// audienceOption is the minimum audience allowed to see the value of the field, e.g. `structviewer:"audience=admin"`.
+// The field is masked for the less privileged audiences.
+const audienceOption = "audience"
+
+// View returns the projection of the configuration exposed to the given audience, where the fields tagged with
+// `structviewer:"audience=..."` are revealed to the audiences at least as privileged as the one of their tag. The
+// Viewer itself is the projection of the least privileged audience.
+func (v *Viewer) View(audience string) (*Viewer, error) {
+	view, ok := v.views[audience]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAudience, audience)
+	}
+
+	return view, nil
+}
+
+// viewFor returns the projection of the configuration exposed to the caller of the given request, chosen with
+// Config.AudienceFunc. Callers of unknown audiences get the projection of the least privileged audience.
+func (v *Viewer) viewFor(r *http.Request) *Viewer {
+	if v.audienceFunc == nil {
+		return v
+	}
+
+	if view, ok := v.views[v.audienceFunc(r)]; ok {
+		return view
+	}
+
+	return v
+}
+
+// startViews generates the projections of the configuration exposed to the audiences more privileged than the one of
+// the Viewer.
+func (v *Viewer) startViews() error {
+	v.views = map[string]*Viewer{v.audience: v}
+
+	for _, audience := range v.audiences {
+		if audience == v.audience {
+			continue
+		}
+
+		view := *v
+		view.audience = audience
+		view.masks = map[string]string{}
+		view.rules = map[string]redactionRule{}
+
+		if err := view.start(); err != nil {
+			return err
+		}
+
+		v.views[audience] = &view
+	}
+
+	return nil
+}
+
+// isVisible reports whether the given field is tagged with an audience that the Viewer is allowed to see the value
+// of, in which case the field is never masked.
+func (v *Viewer) isVisible(field Field) (bool, error) {
+	audience, ok := viewerOption(field.StructField, audienceOption)
+	if !ok {
+		return false, nil
+	}
+
+	level, err := v.audienceLevel(audience)
+	if err != nil {
+		return false, fmt.Errorf("field %s: %w", field.Name, err)
+	}
+
+	current, err := v.audienceLevel(v.audience)
+	if err != nil {
+		return false, err
+	}
+
+	return current >= level, nil
+}
+
+// audienceLevel returns the privilege level of the given audience, the least privileged audience being 0.
+func (v *Viewer) audienceLevel(audience string) (int, error) {
+	for i, a := range v.audiences {
+		if a == audience {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrUnknownAudience, audience)
+}
+
+// copyObject returns a pointer to a shallow copy of the struct pointed by the given pointer.
+func copyObject(object interface{}) interface{} {
+	ptr := reflect.New(reflect.TypeOf(object).Elem())
+	ptr.Elem().Set(reflect.ValueOf(object).Elem())
+
+	return ptr.Interface()
+}
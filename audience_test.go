@@ -0,0 +1,185 @@
+package structviewer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView(t *testing.T) {
+	const redacted = "*REDACTED*"
+
+	config := struct {
+		ListenPort int               `json:"listen_port"`
+		Hostname   string            `json:"hostname" structviewer:"audience=operator"`
+		APIKey     string            `json:"api_key" structviewer:"audience=admin,mode=last4"`
+		Password   string            `json:"password" structviewer:"obfuscate"`
+		Secret     string            `json:"secret" structviewer:"obfuscate,audience=admin"`
+		Headers    map[string]string `json:"headers" structviewer:"obfuscate_values,audience=operator"`
+	}{
+		ListenPort: 8080,
+		Hostname:   "node-1",
+		APIKey:     "api-key-1234",
+		Password:   "password",
+		Secret:     "secret",
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+	}
+
+	viewer, err := New(&Config{Object: config}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		testName string
+		audience string
+		expected []string
+	}{
+		{
+			testName: "public",
+			audience: PublicAudience,
+			expected: []string{
+				"APP_LISTENPORT=8080",
+				"APP_HOSTNAME=" + redacted,
+				"APP_APIKEY=****1234",
+				"APP_PASSWORD=" + redacted,
+				"APP_SECRET=" + redacted,
+				"APP_HEADERS_AUTHORIZATION=" + redacted,
+			},
+		},
+		{
+			testName: "operator",
+			audience: OperatorAudience,
+			expected: []string{
+				"APP_LISTENPORT=8080",
+				"APP_HOSTNAME=node-1",
+				"APP_APIKEY=****1234",
+				"APP_PASSWORD=" + redacted,
+				"APP_SECRET=" + redacted,
+				"APP_HEADERS_AUTHORIZATION=Bearer token",
+			},
+		},
+		{
+			testName: "admin",
+			audience: AdminAudience,
+			expected: []string{
+				"APP_LISTENPORT=8080",
+				"APP_HOSTNAME=node-1",
+				"APP_APIKEY=api-key-1234",
+				"APP_PASSWORD=" + redacted,
+				"APP_SECRET=secret",
+				"APP_HEADERS_AUTHORIZATION=Bearer token",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			view, err := viewer.View(tc.audience)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expected, view.ParseEnvs())
+		})
+	}
+
+	assert.ElementsMatch(t, tcs[0].expected, viewer.ParseEnvs(), "the Viewer exposes the public projection")
+
+	_, err = viewer.View("root")
+	assert.True(t, errors.Is(err, ErrUnknownAudience))
+}
+
+func TestAudienceFunc(t *testing.T) {
+	config := struct {
+		APIKey string `json:"api_key" structviewer:"audience=admin,mode=last4"`
+	}{APIKey: "api-key-1234"}
+
+	viewer, err := New(&Config{
+		Object: config,
+		AudienceFunc: func(r *http.Request) string {
+			return r.Header.Get("X-Audience")
+		},
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		testName string
+		audience string
+		expected string
+	}{
+		{testName: "admin", audience: AdminAudience, expected: "api-key-1234"},
+		{testName: "operator", audience: OperatorAudience, expected: "****1234"},
+		{testName: "no audience", audience: "", expected: "****1234"},
+		{testName: "unknown audience", audience: "root", expected: "****1234"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			handlers := map[string]http.HandlerFunc{
+				"/config":          viewer.ConfigHandler,
+				"/detailed-config": viewer.DetailedConfigHandler,
+				"/envs":            viewer.EnvsHandler,
+			}
+
+			for name, handler := range handlers {
+				req, err := http.NewRequest("GET", name, nil)
+				assert.NoError(t, err)
+				req.Header.Set("X-Audience", tc.audience)
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				assert.Equal(t, http.StatusOK, rr.Code, name)
+				assert.Contains(t, rr.Body.String(), tc.expected, name)
+			}
+		})
+	}
+}
+
+func TestCustomAudiences(t *testing.T) {
+	audiences := []string{"external", "internal"}
+
+	viewer, err := New(&Config{Object: struct {
+		Name string `json:"name" structviewer:"audience=internal"`
+	}{Name: "name"}, Audiences: audiences}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+	assert.Equal(t, []string{"APP_NAME=*REDACTED*"}, viewer.ParseEnvs())
+
+	internal, err := viewer.View("internal")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"APP_NAME=name"}, internal.ParseEnvs())
+
+	_, err = viewer.View(AdminAudience)
+	assert.True(t, errors.Is(err, ErrUnknownAudience))
+
+	_, err = New(&Config{Object: struct {
+		Owner string `json:"owner" structviewer:"audience=admin"`
+	}{Owner: "owner"}, Audiences: audiences}, "APP_")
+	assert.True(t, errors.Is(err, ErrUnknownAudience))
+}
+
+type testViewConfig struct {
+	// Vault is the secret store.
+	Vault testViewVault `json:"vault" structviewer:"audience=admin"`
+}
+
+type testViewVault struct {
+	// Token authenticates to the vault.
+	Token string `json:"token"`
+}
+
+func TestViewDescriptions(t *testing.T) {
+	viewer, err := New(&Config{
+		Object:        testViewConfig{Vault: testViewVault{Token: "token"}},
+		Path:          "./audience_test.go",
+		ParseComments: true,
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "Vault is the secret store.", viewer.EnvNotation("vault").Description)
+	assert.Equal(t, "", viewer.EnvNotation("vault.token").Env, "the vault is masked for the public audience")
+
+	admin := viewer.views[AdminAudience]
+	assert.Equal(t, "APP_VAULT_TOKEN", admin.EnvNotation("vault.token").Env)
+	assert.Equal(t, "Token authenticates to the vault.", admin.EnvNotation("vault.token").Description,
+		"the fields expanded for a privileged audience must be described")
+}
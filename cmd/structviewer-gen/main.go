@@ -0,0 +1,39 @@
+// Command structviewer-gen generates a Go file registering the descriptions of the configuration fields, parsed from
+// their comments, so that binaries shipped without their sources still describe their configuration.
+//
+// Usage, in the package declaring the configuration structure:
+//
+//	//go:generate go run github.com/TykTechnologies/structviewer/cmd/structviewer-gen
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TykTechnologies/structviewer"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package declaring the configuration structure")
+	output := flag.String("output", "structviewer_descriptions.go", "name of the generated file, in dir")
+	types := flag.String("type", "", "comma-separated list of the struct types to describe, all of them if empty")
+
+	flag.Parse()
+
+	var typeNames []string
+	if *types != "" {
+		typeNames = strings.Split(*types, ",")
+	}
+
+	src, err := structviewer.GenerateDescriptions(*dir, typeNames...)
+	if err != nil {
+		log.Fatalf("structviewer-gen: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(*dir, *output), src, 0o644); err != nil {
+		log.Fatalf("structviewer-gen: %v", err)
+	}
+}
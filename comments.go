@@ -0,0 +1,394 @@
+package structviewer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// loadFiles parses the source files of the configuration structure: the files matched by the path of the Viewer, and
+// the files of the packages declaring the named struct types referenced from the configuration structure. Packages
+// whose sources are not available are ignored.
+func (v *Viewer) loadFiles() error {
+	fset := token.NewFileSet()
+	rootType := reflect.TypeOf(v.original).Elem()
+
+	paths, err := sourceFiles(v.confFilePath)
+	if err != nil {
+		return err
+	}
+
+	rootFiles, err := parseFiles(fset, paths)
+	if err != nil {
+		return err
+	}
+
+	v.files = map[string][]*ast.File{rootType.PkgPath(): rootFiles}
+
+	for _, pkgPath := range referencedPackages(rootType) {
+		if _, ok := v.files[pkgPath]; ok {
+			continue
+		}
+
+		pkg, err := build.Import(pkgPath, ".", 0)
+		if err != nil {
+			continue
+		}
+
+		files, err := parseFiles(fset, packageFiles(pkg))
+		if err != nil {
+			continue
+		}
+
+		v.files[pkgPath] = files
+	}
+
+	return nil
+}
+
+// sourceFiles returns the non-test Go files matched by the given path, which is either a file, a package directory,
+// a glob of files, e.g. './config/*.go', or an import path. The errors of the paths matching no sources wrap
+// fs.ErrNotExist.
+func sourceFiles(path string) ([]string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var paths []string
+
+		for _, match := range matches {
+			if strings.HasSuffix(match, ".go") && !strings.HasSuffix(match, "_test.go") {
+				paths = append(paths, match)
+			}
+		}
+
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no Go files match %s: %w", path, fs.ErrNotExist)
+		}
+
+		return paths, nil
+	}
+
+	info, err := os.Stat(path)
+	if err == nil && !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	if err != nil {
+		pkg, importErr := build.Import(path, ".", 0)
+		if importErr != nil {
+			// The path is neither a file, a directory nor an import path: the sources are not available.
+			return nil, fmt.Errorf("%w: %w", importErr, err)
+		}
+
+		return packageFiles(pkg), nil
+	}
+
+	pkg, err := build.ImportDir(path, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return packageFiles(pkg), nil
+}
+
+// packageFiles returns the paths of the non-test Go files of the given package.
+func packageFiles(pkg *build.Package) []string {
+	paths := make([]string, 0, len(pkg.GoFiles)+len(pkg.CgoFiles))
+
+	for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+		paths = append(paths, filepath.Join(pkg.Dir, name))
+	}
+
+	return paths
+}
+
+// parseFiles parses the given Go files, keeping their comments.
+func parseFiles(fset *token.FileSet, paths []string) ([]*ast.File, error) {
+	files := make([]*ast.File, 0, len(paths))
+
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// referencedPackages returns the sorted paths of the packages declaring the named struct types walked by the Viewer
+// from the given type. Types rendered by a formatter, e.g. time.Time, are not walked.
+func referencedPackages(t reflect.Type) []string {
+	var pkgPaths []string
+
+	collectPackages(t, map[reflect.Type]bool{}, map[string]bool{}, &pkgPaths)
+	sort.Strings(pkgPaths)
+
+	return pkgPaths
+}
+
+// collectPackages appends the paths of the packages declaring the named struct types walked from the given type to
+// pkgPaths.
+func collectPackages(t reflect.Type, visited map[reflect.Type]bool, seen map[string]bool, pkgPaths *[]string) {
+	if visited[t] || formatterFor(t) != nil {
+		return
+	}
+
+	visited[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		collectPackages(t.Elem(), visited, seen, pkgPaths)
+	case reflect.Struct:
+		if pkgPath := t.PkgPath(); pkgPath != "" && !seen[pkgPath] {
+			seen[pkgPath] = true
+			*pkgPaths = append(*pkgPaths, pkgPath)
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() || t.Field(i).Anonymous {
+				collectPackages(t.Field(i).Type, visited, seen, pkgPaths)
+			}
+		}
+	default:
+	}
+}
+
+// commentResolver attaches the comments of the fields to their EnvVar, following the declarations of their types.
+type commentResolver struct {
+	// files are the parsed source files, indexed by their package path.
+	files map[string][]*ast.File
+	// envs are the EnvVar of the configuration structure, indexed by their Go path. The views of the configuration
+	// exposed to the audiences hold distinct EnvVar with the same Go path.
+	envs map[string][]*EnvVar
+	// paths are the Go paths of the EnvVar and of their parents, e.g. 'Base' for the promoted 'Base.Port' field.
+	paths map[string]bool
+}
+
+func newCommentResolver(files map[string][]*ast.File, envs []*EnvVar) *commentResolver {
+	r := &commentResolver{files: files, envs: map[string][]*EnvVar{}, paths: map[string]bool{}}
+	r.index(envs)
+
+	return r
+}
+
+// index indexes the given EnvVar and their nested EnvVar by Go path.
+func (r *commentResolver) index(envs []*EnvVar) {
+	for _, env := range envs {
+		r.envs[env.goPath] = append(r.envs[env.goPath], env)
+
+		for goPath := env.goPath; ; goPath = goPath[:strings.LastIndex(goPath, ".")] {
+			r.paths[goPath] = true
+
+			if !strings.Contains(goPath, ".") {
+				break
+			}
+		}
+
+		if nested, ok := env.Value.(map[string]*EnvVar); ok && env.isStruct {
+			for _, nestedEnv := range nested {
+				r.index([]*EnvVar{nestedEnv})
+			}
+		}
+	}
+}
+
+// describeType describes the fields of the value with the given Go path, whose type is given by the type expression
+// expr of the given file. The root value has an empty Go path.
+func (r *commentResolver) describeType(expr ast.Expr, file *ast.File, pkgPath, goPath string) {
+	if goPath != "" && !r.paths[goPath] {
+		return
+	}
+
+	switch t := unwrapStarExpr(expr).(type) {
+	case *ast.StructType:
+		r.describeFields(t, file, pkgPath, goPath)
+	case *ast.Ident:
+		if spec, specFile := r.typeSpec(pkgPath, t.Name); spec != nil {
+			r.describeType(spec.Type, specFile, pkgPath, goPath)
+		}
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return
+		}
+
+		importPath := r.importPath(file, pkg.Name)
+		if spec, specFile := r.typeSpec(importPath, t.Sel.Name); spec != nil {
+			r.describeType(spec.Type, specFile, importPath, goPath)
+		}
+	case *ast.ArrayType:
+		r.describeElements(t.Elt, file, pkgPath, goPath)
+	case *ast.MapType:
+		r.describeElements(t.Value, file, pkgPath, goPath)
+	default:
+	}
+}
+
+// describeFields describes the fields of the given struct type, and the fields of their own types.
+func (r *commentResolver) describeFields(s *ast.StructType, file *ast.File, pkgPath, goPath string) {
+	for _, field := range s.Fields.List {
+		doc := fieldDoc(field)
+
+		for _, name := range fieldNames(field) {
+			fieldGoPath := joinGoPath(goPath, name)
+
+			for _, env := range r.envs[fieldGoPath] {
+				env.setDoc(doc)
+			}
+
+			r.describeType(field.Type, file, pkgPath, fieldGoPath)
+		}
+	}
+}
+
+// describeElements describes the elements of the slice, array or map with the given Go path, whose type is given by
+// the type expression elem.
+func (r *commentResolver) describeElements(elem ast.Expr, file *ast.File, pkgPath, goPath string) {
+	for _, elementGoPath := range r.elements(goPath) {
+		r.describeType(elem, file, pkgPath, elementGoPath)
+	}
+}
+
+// elements returns the sorted Go paths of the elements of the slices, arrays or maps with the given Go path.
+func (r *commentResolver) elements(goPath string) []string {
+	var elementGoPaths []string
+
+	seen := map[string]bool{}
+
+	for _, env := range r.envs[goPath] {
+		elements, _ := env.Value.(map[string]*EnvVar)
+		for _, element := range elements {
+			if !seen[element.goPath] {
+				seen[element.goPath] = true
+				elementGoPaths = append(elementGoPaths, element.goPath)
+			}
+		}
+	}
+
+	sort.Strings(elementGoPaths)
+
+	return elementGoPaths
+}
+
+// typeSpec returns the declaration of the type with the given name in the given package, along with its file.
+func (r *commentResolver) typeSpec(pkgPath, name string) (*ast.TypeSpec, *ast.File) {
+	for _, file := range r.files[pkgPath] {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok && typeSpec.Name.Name == name {
+					return typeSpec, file
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// anonymousStruct returns the first struct type literal of the given files whose fields are the fields of the given
+// anonymous struct type, along with its file.
+func anonymousStruct(files []*ast.File, t reflect.Type) (*ast.StructType, *ast.File) {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, t.Field(i).Name)
+	}
+
+	for _, file := range files {
+		var found *ast.StructType
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			s, ok := n.(*ast.StructType)
+			if found != nil || !ok {
+				return found == nil
+			}
+
+			var fields []string
+			for _, field := range s.Fields.List {
+				fields = append(fields, fieldNames(field)...)
+			}
+
+			if reflect.DeepEqual(fields, names) {
+				found = s
+			}
+
+			return found == nil
+		})
+
+		if found != nil {
+			return found, file
+		}
+	}
+
+	return nil, nil
+}
+
+// importPath returns the path of the package imported with the given name in the given file. Packages imported
+// without an explicit name are identified by the package clause of their parsed files.
+func (r *commentResolver) importPath(file *ast.File, name string) string {
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return importPath
+			}
+
+			continue
+		}
+
+		if files := r.files[importPath]; len(files) > 0 && files[0].Name.Name == name {
+			return importPath
+		}
+	}
+
+	return ""
+}
+
+// fieldNames returns the names of the given field. Embedded fields are named after their type.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{embeddedName(field.Type)}
+	}
+
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+
+	return names
+}
+
+// embeddedName returns the field name of an embedded field of the given type, e.g. 'Base' for '*pkg.Base'.
+func embeddedName(expr ast.Expr) string {
+	switch t := unwrapStarExpr(expr).(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,124 @@
+package structviewer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/structviewer/internal/testconfig"
+)
+
+func TestParseCommentsAcrossFiles(t *testing.T) {
+	tcs := []struct {
+		testName  string
+		givenPath string
+	}{
+		{testName: "package directory", givenPath: "./internal/testconfig"},
+		{testName: "glob", givenPath: "./internal/testconfig/*.go"},
+		{testName: "import path", givenPath: "github.com/TykTechnologies/structviewer/internal/testconfig"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{
+				Object:        testconfig.Config{},
+				Path:          tc.givenPath,
+				ParseComments: true,
+			}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.Equal(t, "ListenPort is the port the server listens on.",
+				viewer.EnvNotation("listen_port").Description)
+			assert.Equal(t, "Addr is the address of the storage.", viewer.EnvNotation("storage.addr").Description)
+			assert.Equal(t, "Rate is the number of requests allowed per second.",
+				viewer.EnvNotation("limits.rate").Description)
+		})
+	}
+}
+
+func TestParseCommentsByType(t *testing.T) {
+	viewer, err := New(&Config{
+		Object: testconfig.Config{
+			Upstreams: map[string]testconfig.Upstream{"main": {URL: "http://localhost"}},
+		},
+		Path:          "./internal/testconfig",
+		ParseComments: true,
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		configField         string
+		expectedDescription string
+	}{
+		{configField: "name", expectedDescription: "Name is the name of the service."},
+		{configField: "listen_port", expectedDescription: "ListenPort is the port the server listens on."},
+		{configField: "storage.enabled", expectedDescription: "Enabled enables the storage."},
+		{configField: "limits.enabled", expectedDescription: "Enabled enables the rate limits."},
+		{configField: "upstreams.main.url", expectedDescription: "URL is the URL of the upstream."},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDescription, viewer.EnvNotation(tc.configField).Description)
+		})
+	}
+}
+
+func TestSourceFiles(t *testing.T) {
+	tcs := []struct {
+		testName      string
+		givenPath     string
+		expectedFiles []string
+		expectedErr   bool
+	}{
+		{
+			testName:      "file",
+			givenPath:     "./parser_test.go",
+			expectedFiles: []string{"./parser_test.go"},
+		},
+		{
+			testName:      "glob without test files",
+			givenPath:     "./comments*.go",
+			expectedFiles: []string{"comments.go"},
+		},
+		{
+			testName:    "glob without matches",
+			givenPath:   "./missing/*.go",
+			expectedErr: true,
+		},
+		{
+			testName:    "missing package",
+			givenPath:   "./missing",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			files, err := sourceFiles(tc.givenPath)
+			assert.Equal(t, tc.expectedErr, err != nil, "unexpected error: %v", err)
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
+
+func TestParseCommentsAnonymousRoot(t *testing.T) {
+	config := struct {
+		// ListenPort is the port of the anonymous configuration.
+		ListenPort int `json:"listen_port"`
+		Storage    struct {
+			// Addr is the address of the anonymous storage.
+			Addr string `json:"addr"`
+		} `json:"storage"`
+	}{}
+
+	viewer, err := New(&Config{Object: config, Path: "./comments_test.go", ParseComments: true}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "ListenPort is the port of the anonymous configuration.",
+		viewer.EnvNotation("listen_port").Description)
+	assert.Equal(t, "Addr is the address of the anonymous storage.", viewer.EnvNotation("storage.addr").Description)
+
+	_, err = New(&Config{Object: struct{ Missing int }{}, Path: "./comments.go", ParseComments: true}, "APP_")
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package structviewer
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodeValue returns the value of the given type represented by the given string in its environment variable
+// notation, e.g. '1m30s' for a time.Duration, 'a,b,c' for a slice or 'k1:v1,k2:v2' for a map, as envconfig decodes
+// it.
+func decodeValue(s string, t reflect.Type) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+
+	if err := decodeInto(s, value); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return value, nil
+}
+
+// decodeInto sets the given settable value to the value represented by the given string.
+func decodeInto(s string, value reflect.Value) error {
+	t := value.Type()
+
+	if t.Kind() == reflect.Ptr {
+		elem := reflect.New(t.Elem())
+		if err := decodeInto(s, elem.Elem()); err != nil {
+			return err
+		}
+
+		value.Set(elem)
+
+		return nil
+	}
+
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(s))
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(d))
+	case t == urlType:
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+
+		value.Set(reflect.ValueOf(*u))
+	case t.Kind() == reflect.String:
+		value.SetString(s)
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		value.SetBool(b)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(i)
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetUint(u)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetFloat(f)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		value.SetBytes([]byte(s))
+	case t.Kind() == reflect.Slice:
+		return decodeSlice(s, value)
+	case t.Kind() == reflect.Map:
+		return decodeMap(s, value)
+	default:
+		return fmt.Errorf("cannot decode %q into %s", s, typeName(t))
+	}
+
+	return nil
+}
+
+// decodeSlice sets the given slice to the comma-separated values of the given string.
+func decodeSlice(s string, value reflect.Value) error {
+	slice := reflect.MakeSlice(value.Type(), 0, 0)
+
+	if s != "" {
+		for _, elem := range strings.Split(s, ",") {
+			decoded, err := decodeValue(strings.TrimSpace(elem), value.Type().Elem())
+			if err != nil {
+				return err
+			}
+
+			slice = reflect.Append(slice, decoded)
+		}
+	}
+
+	value.Set(slice)
+
+	return nil
+}
+
+// decodeMap sets the given map to the comma-separated 'key:value' pairs of the given string.
+func decodeMap(s string, value reflect.Value) error {
+	m := reflect.MakeMap(value.Type())
+
+	if s != "" {
+		for _, pair := range strings.Split(s, ",") {
+			k, v, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("invalid map item %q", pair)
+			}
+
+			key, err := decodeValue(strings.TrimSpace(k), value.Type().Key())
+			if err != nil {
+				return err
+			}
+
+			elem, err := decodeValue(strings.TrimSpace(v), value.Type().Elem())
+			if err != nil {
+				return err
+			}
+
+			m.SetMapIndex(key, elem)
+		}
+	}
+
+	value.Set(m)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package structviewer
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeValue(t *testing.T) {
+	port := 8080
+
+	tcs := []struct {
+		testName    string
+		givenValue  string
+		givenType   reflect.Type
+		expected    interface{}
+		expectedErr bool
+	}{
+		{testName: "string", givenValue: "localhost", givenType: reflect.TypeOf(""), expected: "localhost"},
+		{testName: "int", givenValue: "8080", givenType: reflect.TypeOf(0), expected: 8080},
+		{testName: "uint8", givenValue: "255", givenType: reflect.TypeOf(uint8(0)), expected: uint8(255)},
+		{testName: "float", givenValue: "0.5", givenType: reflect.TypeOf(0.0), expected: 0.5},
+		{testName: "bool", givenValue: "true", givenType: reflect.TypeOf(false), expected: true},
+		{
+			testName: "duration", givenValue: "1m30s", givenType: reflect.TypeOf(time.Duration(0)),
+			expected: 90 * time.Second,
+		},
+		{testName: "pointer", givenValue: "8080", givenType: reflect.TypeOf(&port), expected: &port},
+		{
+			testName: "slice", givenValue: "a, b", givenType: reflect.TypeOf([]string{}),
+			expected: []string{"a", "b"},
+		},
+		{testName: "empty slice", givenValue: "", givenType: reflect.TypeOf([]int{}), expected: []int{}},
+		{testName: "byte slice", givenValue: "a,b", givenType: reflect.TypeOf([]byte{}), expected: []byte("a,b")},
+		{
+			testName: "map", givenValue: "a:1,b:2", givenType: reflect.TypeOf(map[string]int{}),
+			expected: map[string]int{"a": 1, "b": 2},
+		},
+		{
+			testName: "text unmarshaler", givenValue: "127.0.0.1", givenType: reflect.TypeOf(net.IP{}),
+			expected: net.ParseIP("127.0.0.1"),
+		},
+		{
+			testName: "url", givenValue: "https://example.com", givenType: reflect.TypeOf(url.URL{}),
+			expected: url.URL{Scheme: "https", Host: "example.com"},
+		},
+		{testName: "invalid int", givenValue: "port", givenType: reflect.TypeOf(0), expectedErr: true},
+		{testName: "invalid map", givenValue: "a", givenType: reflect.TypeOf(map[string]int{}), expectedErr: true},
+		{testName: "struct", givenValue: "a", givenType: reflect.TypeOf(struct{}{}), expectedErr: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			value, err := decodeValue(tc.givenValue, tc.givenType)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, value.Interface())
+		})
+	}
+}
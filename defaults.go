@@ -0,0 +1,261 @@
+package structviewer
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// defaultTag is the tag of the default values of the fields, as read by github.com/kelseyhightower/envconfig.
+const defaultTag = "default"
+
+// ModifiedQueryKey is the query key of the handlers exposing only the fields which differ from their default value,
+// e.g. '?modified=true'.
+const ModifiedQueryKey = "modified"
+
+// EnvFilter selects the fields returned by ParseEnvs.
+type EnvFilter func(env *EnvVar) bool
+
+// Modified selects the fields whose value differs from their default value. Fields without a known default value
+// are compared to the zero value of their type.
+func Modified(env *EnvVar) bool {
+	return env.modified
+}
+
+// newDefaults returns a pointer to the default configuration structure of the given type: a copy of the given
+// defaults instance, or the zero value, whose fields tagged with `default:"..."` are set to their tag unless the
+// defaults instance is given. It also returns the Go paths of the tagged fields.
+func newDefaults(t reflect.Type, instance interface{}) (interface{}, map[string]bool, error) {
+	defaults := reflect.New(t)
+
+	if instance != nil {
+		value := indirect(reflect.ValueOf(instance))
+		if value.Type() != t {
+			return nil, nil, fmt.Errorf("%w: defaults of type %s instead of %s", ErrInvalidObjectType, value.Type(), t)
+		}
+
+		defaults.Elem().Set(value)
+	}
+
+	tagged := map[string]bool{}
+	if err := applyDefaultTags(defaults.Elem(), "", tagged, instance == nil, map[reflect.Type]bool{}); err != nil {
+		return nil, nil, err
+	}
+
+	return defaults.Interface(), tagged, nil
+}
+
+// applyDefaultTags records the Go paths of the fields of the given struct tagged with `default:"..."`, and sets the
+// fields to their tag if apply is true. Nested structs are walked, allocating the nil pointers to structs holding
+// tagged fields, except the structs of the types being walked, which would be allocated endlessly.
+func applyDefaultTags(s reflect.Value,
+	goPath string,
+	tagged map[string]bool,
+	apply bool,
+	visited map[reflect.Type]bool,
+) error {
+	visited[s.Type()] = true
+	defer delete(visited, s.Type())
+
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Type().Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		fieldGoPath := joinGoPath(goPath, field.Name)
+		fieldValue := s.Field(i)
+
+		if tag, ok := field.Tag.Lookup(defaultTag); ok {
+			tagged[fieldGoPath] = true
+
+			if !apply {
+				continue
+			}
+
+			value, err := decodeValue(tag, field.Type)
+			if err != nil {
+				return fmt.Errorf("field %s: invalid default value %s: %w", fieldGoPath, strconv.Quote(tag), err)
+			}
+
+			fieldValue.Set(value)
+
+			continue
+		}
+
+		structType := field.Type
+		for structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+
+		if structType.Kind() != reflect.Struct || formatterFor(structType) != nil || visited[structType] ||
+			!hasDefaultTags(structType, map[reflect.Type]bool{}) {
+			continue
+		}
+
+		for fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				if !apply {
+					break
+				}
+
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+
+			fieldValue = fieldValue.Elem()
+		}
+
+		if fieldValue.Kind() != reflect.Struct {
+			continue
+		}
+
+		if err := applyDefaultTags(fieldValue, fieldGoPath, tagged, apply, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasDefaultTags reports whether the given struct type, or one of its nested structs, has fields tagged with
+// `default:"..."`. visited holds the struct types being walked, which are not walked again.
+func hasDefaultTags(t reflect.Type, visited map[reflect.Type]bool) bool {
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup(defaultTag); ok {
+			return true
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct && !visited[fieldType] && formatterFor(fieldType) == nil &&
+			hasDefaultTags(fieldType, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// annotateDefaults sets the default values of the fields, whether they are set to them and whether they are
+// modified. Values are compared in their environment variable notation, before being masked. The masked fields are
+// not annotated.
+func (v *Viewer) annotateDefaults() {
+	unmasked := *v
+	unmasked.masks = map[string]string{}
+
+	current := indexEnvs(unmasked.parseEnvs(v.original, v.prefix, "", ""), map[string]*EnvVar{})
+	defaults := indexEnvs(unmasked.parseEnvs(v.defaults, v.prefix, "", ""), map[string]*EnvVar{})
+
+	v.annotateEnvs(v.envs, current, defaults)
+}
+
+// annotateEnvs annotates the given EnvVar with the default values of the given defaults, indexed by Go path, and
+// reports whether one of them is modified.
+func (v *Viewer) annotateEnvs(envs []*EnvVar, current, defaults map[string]*EnvVar) bool {
+	modified := false
+
+	for _, env := range envs {
+		if nested, ok := env.Value.(map[string]*EnvVar); ok && env.isStruct {
+			nestedEnvs := make([]*EnvVar, 0, len(nested))
+			for _, nestedEnv := range nested {
+				nestedEnvs = append(nestedEnvs, nestedEnv)
+			}
+
+			env.modified = v.annotateEnvs(nestedEnvs, current, defaults)
+			modified = modified || env.modified
+
+			continue
+		}
+
+		if _, masked := v.masks[env.goPath]; masked || v.hasMasks(env.goPath) {
+			// Comparing the masked values to their default would reveal whether they are set to it, so they are
+			// neither reported as default nor as modified.
+			env.modified = false
+			continue
+		}
+
+		value, def := current[env.goPath], defaults[env.goPath]
+		env.modified = value == nil || def == nil || value.envValue != def.envValue
+		modified = modified || env.modified
+
+		if def == nil || !v.hasDefault(env.goPath) {
+			continue
+		}
+
+		env.IsDefault = getPointerBool(!env.modified)
+		env.Default = def.Value
+	}
+
+	return modified
+}
+
+// hasDefault reports whether the default value of the value with the given Go path is known: either the defaults
+// instance is given, or the value or one of its parents is tagged with `default:"..."`.
+func (v *Viewer) hasDefault(goPath string) bool {
+	if v.hasDefaults {
+		return true
+	}
+
+	for p := goPath; p != ""; p = parentGoPath(p) {
+		if v.defaultTags[p] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexEnvs adds the given EnvVar and their nested EnvVar to the given index, by Go path.
+func indexEnvs(envs []*EnvVar, index map[string]*EnvVar) map[string]*EnvVar {
+	for _, env := range envs {
+		index[env.goPath] = env
+
+		if nested, ok := env.Value.(map[string]*EnvVar); ok && env.isStruct {
+			for _, nestedEnv := range nested {
+				indexEnvs([]*EnvVar{nestedEnv}, index)
+			}
+		}
+	}
+
+	return index
+}
+
+// modifiedOnly reports whether the given request only asks for the modified fields.
+func modifiedOnly(r *http.Request) bool {
+	modified, _ := strconv.ParseBool(r.URL.Query().Get(ModifiedQueryKey))
+
+	return modified
+}
+
+// filterEnvs returns copies of the given EnvVar selected by the given filter, keeping the structs holding selected
+// fields.
+func filterEnvs(envs map[string]*EnvVar, filter EnvFilter) map[string]*EnvVar {
+	filtered := map[string]*EnvVar{}
+
+	for key, env := range envs {
+		nested, ok := env.Value.(map[string]*EnvVar)
+		if !ok || !env.isStruct {
+			if filter(env) {
+				filtered[key] = env
+			}
+
+			continue
+		}
+
+		if nested = filterEnvs(nested, filter); len(nested) > 0 {
+			envCopy := *env
+			envCopy.Value = nested
+			filtered[key] = &envCopy
+		}
+	}
+
+	return filtered
+}
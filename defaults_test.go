@@ -0,0 +1,131 @@
+package structviewer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTags(t *testing.T) {
+	type tls struct {
+		Enabled bool `json:"enabled" default:"true"`
+	}
+
+	config := struct {
+		Port     int               `json:"port" default:"8080"`
+		Host     string            `json:"host" default:"localhost"`
+		Timeout  time.Duration     `json:"timeout" default:"30s"`
+		Tags     []string          `json:"tags" default:"a,b"`
+		Labels   map[string]string `json:"labels" default:"env:dev"`
+		Password string            `json:"password" default:"changeme" structviewer:"obfuscate"`
+		APIKey   string            `json:"api_key" default:"changeme" structviewer:"obfuscate"`
+		Name     string            `json:"name"`
+		TLS      *tls              `json:"tls"`
+	}{
+		Port:     9090,
+		Host:     "localhost",
+		Timeout:  30 * time.Second,
+		Tags:     []string{"a", "c"},
+		Labels:   map[string]string{"env": "dev"},
+		Password: "changeme",
+		APIKey:   "rotated",
+		Name:     "gateway",
+		TLS:      &tls{Enabled: true},
+	}
+
+	viewer, err := New(&Config{Object: config}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		configField       string
+		expectedDefault   interface{}
+		expectedIsDefault *bool
+	}{
+		{configField: "port", expectedDefault: 8080, expectedIsDefault: getPointerBool(false)},
+		{configField: "host", expectedDefault: "localhost", expectedIsDefault: getPointerBool(true)},
+		{configField: "timeout", expectedDefault: "30s", expectedIsDefault: getPointerBool(true)},
+		{configField: "tags.0", expectedDefault: "a", expectedIsDefault: getPointerBool(true)},
+		{configField: "tags.1", expectedDefault: "b", expectedIsDefault: getPointerBool(false)},
+		{configField: "labels.env", expectedDefault: "dev", expectedIsDefault: getPointerBool(true)},
+		{configField: "password", expectedDefault: nil, expectedIsDefault: nil},
+		{configField: "api_key", expectedDefault: nil, expectedIsDefault: nil},
+		{configField: "name", expectedDefault: nil, expectedIsDefault: nil},
+		{configField: "tls.enabled", expectedDefault: true, expectedIsDefault: getPointerBool(true)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			envVar := viewer.EnvNotation(tc.configField)
+			assert.Equal(t, tc.expectedDefault, envVar.Default)
+			assert.Equal(t, tc.expectedIsDefault, envVar.IsDefault)
+		})
+	}
+
+	assert.ElementsMatch(t, []string{"APP_PORT=9090", "APP_TAGS_1=c", "APP_NAME=gateway"}, viewer.ParseEnvs(Modified))
+
+	t.Run("modified query", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/envs?modified=true", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(viewer.EnvsHandler).ServeHTTP(rr, req)
+
+		var envs []string
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &envs))
+		assert.ElementsMatch(t, []string{"APP_NAME=gateway", "APP_PORT=9090", "APP_TAGS_1=c"}, envs)
+
+		req, err = http.NewRequest("GET", "/detailed-config?modified=true", nil)
+		assert.NoError(t, err)
+
+		rr = httptest.NewRecorder()
+		http.HandlerFunc(viewer.DetailedConfigHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"Name": {"config_field": "name", "env": "APP_NAME", "value": "gateway", "type": "string",
+				"obfuscated": false},
+			"Port": {"config_field": "port", "env": "APP_PORT", "value": 9090, "type": "int", "obfuscated": false,
+				"default": 8080, "is_default": false},
+			"Tags": {"value": {
+				"1": {"config_field": "tags.1", "env": "APP_TAGS_1", "value": "c", "type": "string",
+					"obfuscated": false, "default": "b", "is_default": false}
+			}}
+		}`, rr.Body.String())
+	})
+}
+
+func TestDefaultsInstance(t *testing.T) {
+	type config struct {
+		Port int    `json:"port" default:"8080"`
+		Host string `json:"host" default:"localhost"`
+		Name string `json:"name"`
+	}
+
+	defaults := config{Port: 80, Host: "localhost", Name: "gateway"}
+	modified := config{Port: 80, Host: "example.com", Name: "gateway"}
+
+	viewer, err := New(&Config{Object: modified, Defaults: &defaults}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "gateway", viewer.EnvNotation("name").Default)
+	assert.Equal(t, getPointerBool(true), viewer.EnvNotation("name").IsDefault)
+	assert.Equal(t, 80, viewer.EnvNotation("port").Default, "the defaults instance overrides the tags")
+	assert.Equal(t, []string{"APP_HOST=example.com"}, viewer.ParseEnvs(Modified))
+}
+
+func TestInvalidDefaults(t *testing.T) {
+	_, err := New(&Config{Object: struct {
+		Port int `default:"port"`
+	}{}}, "APP_")
+	assert.ErrorContains(t, err, `field Port: invalid default value "port"`)
+
+	_, err = New(&Config{Object: struct{ Port int }{}, Defaults: struct{ Host string }{}}, "APP_")
+	assert.True(t, errors.Is(err, ErrInvalidObjectType))
+}
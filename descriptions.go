@@ -0,0 +1,416 @@
+package structviewer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	descriptionsMu sync.RWMutex
+	// descriptions is the registry of field descriptions, indexed by the struct type declaring the fields.
+	descriptions = map[reflect.Type]map[string]FieldDoc{}
+)
+
+// DescriptionSource is a source of the descriptions of the fields.
+type DescriptionSource string
+
+// Sources of the descriptions of the fields.
+const (
+	// TagDescriptionSource is the `desc:"..."` tag of the fields, or the desc option of their structviewer tag, e.g.
+	// `structviewer:"obfuscate,desc=listening port"`.
+	TagDescriptionSource DescriptionSource = "tag"
+	// DocDescriptionSource is the doc comment of the fields, preceding them.
+	DocDescriptionSource DescriptionSource = "doc"
+	// LineCommentDescriptionSource is the trailing line comment of the fields, e.g. 'Port int // listening port'.
+	LineCommentDescriptionSource DescriptionSource = "comment"
+)
+
+// DefaultDescriptionSources is the precedence order of the description sources when none is configured: tags
+// override the doc comments, which override the line comments.
+var DefaultDescriptionSources = []DescriptionSource{
+	TagDescriptionSource,
+	DocDescriptionSource,
+	LineCommentDescriptionSource,
+}
+
+// validateDescriptionSources returns an error if one of the given sources is unknown.
+func validateDescriptionSources(sources []DescriptionSource) error {
+	for _, source := range sources {
+		switch source {
+		case TagDescriptionSource, DocDescriptionSource, LineCommentDescriptionSource:
+		default:
+			return fmt.Errorf("%w: %q", ErrUnknownDescriptionSource, source)
+		}
+	}
+
+	return nil
+}
+
+// describeViews sets the descriptions of the fields of the Viewer and of its views to the first description found in
+// the sources of the Viewer: their registered descriptions and, if parseComments is true, their comments. The fields
+// of all the views are described at once, since the fields masked for an audience may be expanded for another one.
+func (v *Viewer) describeViews(parseComments bool) error {
+	var envs []*EnvVar
+	for _, audience := range v.audiences {
+		envs = append(envs, v.views[audience].envs...)
+	}
+
+	registered := v.describeRegistered(envs)
+
+	if parseComments {
+		// Binaries shipped with generated descriptions don't need their sources, but the other errors, e.g. syntax
+		// errors, are still reported.
+		if err := v.parseComments(envs); err != nil && (!registered || !errors.Is(err, fs.ErrNotExist)) {
+			return err
+		}
+	}
+
+	describeEnvs(envs, v.descriptionSources)
+
+	return nil
+}
+
+func describeEnvs(envs []*EnvVar, sources []DescriptionSource) {
+	for _, env := range envs {
+		for _, source := range sources {
+			if description := env.description(source); description != "" {
+				env.Description = description
+				break
+			}
+		}
+
+		if nested, ok := env.Value.(map[string]*EnvVar); ok && env.isStruct {
+			for _, nestedEnv := range nested {
+				describeEnvs([]*EnvVar{nestedEnv}, sources)
+			}
+		}
+	}
+}
+
+// description returns the description of the given struct field given by the given source.
+func (ev *EnvVar) description(source DescriptionSource) string {
+	switch source {
+	case TagDescriptionSource:
+		return ev.tagDescription
+	case DocDescriptionSource:
+		return ev.doc
+	case LineCommentDescriptionSource:
+		return ev.lineComment
+	default:
+		return ""
+	}
+}
+
+// setDoc sets the comments of the given struct field, keeping the existing ones if the given ones are empty.
+func (ev *EnvVar) setDoc(doc FieldDoc) {
+	if doc.Doc != "" {
+		ev.doc = doc.Doc
+	}
+
+	if doc.Comment != "" {
+		ev.lineComment = doc.Comment
+	}
+}
+
+// tagDescription returns the description of the given field given by its `desc:"..."` tag, or by the desc option of
+// its structviewer tag, unquoting it if it is single-quoted.
+func tagDescription(field reflect.StructField) string {
+	if description, ok := field.Tag.Lookup(descOption); ok {
+		return strings.TrimSpace(description)
+	}
+
+	description, _ := viewerOption(field, descOption)
+	description = strings.TrimSpace(description)
+
+	if len(description) > 1 && strings.HasPrefix(description, "'") && strings.HasSuffix(description, "'") {
+		description = strings.TrimSpace(description[1 : len(description)-1])
+	}
+
+	return description
+}
+
+// fieldDoc returns the doc comment and the line comment of the given field.
+func fieldDoc(field *ast.Field) FieldDoc {
+	return FieldDoc{Doc: commentText(field.Doc), Comment: commentText(field.Comment)}
+}
+
+// commentText returns the text of the given comment, joining the lines of its paragraphs. Paragraphs are separated
+// by a blank line, and indented paragraphs such as code blocks are kept as they are.
+func commentText(group *ast.CommentGroup) string {
+	paragraphs := strings.Split(strings.TrimSpace(group.Text()), "\n\n")
+
+	for i, paragraph := range paragraphs {
+		if !strings.Contains(paragraph, "\n ") && !strings.Contains(paragraph, "\n\t") &&
+			!strings.HasPrefix(paragraph, " ") && !strings.HasPrefix(paragraph, "\t") {
+			paragraphs[i] = strings.ReplaceAll(paragraph, "\n", " ")
+		}
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// FieldDoc is the documentation of a field, parsed from its comments.
+type FieldDoc struct {
+	// Doc is the doc comment of the field, preceding it. Its paragraphs are separated by a blank line.
+	Doc string
+	// Comment is the trailing line comment of the field, e.g. 'listening port' for 'Port int // listening port'.
+	Comment string
+}
+
+// RegisterDescriptions registers the descriptions of the fields of the given struct type as their doc comments,
+// replacing the existing ones if any. Descriptions are indexed by the Go path of the fields from the type, e.g. 'Port'
+// or 'Data.Port' for the fields of an inline struct. Registered descriptions are used by every Viewer, whether it
+// parses comments or not, so that binaries shipped without their sources still describe their configuration.
+//
+// RegisterDescriptions is kept for the files generated by the first versions of structviewer-gen. RegisterFieldDocs
+// also registers the line comments of the fields.
+func RegisterDescriptions(t reflect.Type, fieldDescriptions map[string]string) {
+	fieldDocs := make(map[string]FieldDoc, len(fieldDescriptions))
+	for key, description := range fieldDescriptions {
+		fieldDocs[key] = FieldDoc{Doc: description}
+	}
+
+	RegisterFieldDocs(t, fieldDocs)
+}
+
+// RegisterFieldDocs registers the doc comments and the line comments of the fields of the given struct type, replacing
+// the existing ones if any, as RegisterDescriptions does.
+//
+// RegisterFieldDocs is called by the files generated with
+// 'go run github.com/TykTechnologies/structviewer/cmd/structviewer-gen'.
+func RegisterFieldDocs(t reflect.Type, fieldDocs map[string]FieldDoc) {
+	descriptionsMu.Lock()
+	defer descriptionsMu.Unlock()
+
+	descriptions[t] = fieldDocs
+}
+
+// registeredDescriptions returns the descriptions registered for the given type.
+func registeredDescriptions(t reflect.Type) (map[string]FieldDoc, bool) {
+	descriptionsMu.RLock()
+	defer descriptionsMu.RUnlock()
+
+	fieldDescriptions, ok := descriptions[t]
+
+	return fieldDescriptions, ok
+}
+
+// describeRegistered sets the comments of the given EnvVar to their registered descriptions. It reports whether
+// descriptions are registered for the type of the configuration structure.
+func (v *Viewer) describeRegistered(envs []*EnvVar) bool {
+	rootType := reflect.TypeOf(v.original).Elem()
+	_, ok := registeredDescriptions(rootType)
+
+	newCommentResolver(nil, envs).describeRegistered(rootType, "", "", nil)
+
+	return ok
+}
+
+// describeRegistered describes the fields of the value with the given Go path, of the given type, with the registered
+// descriptions. fieldDescriptions are the descriptions of the closest named struct type declaring the value, and rel
+// is the Go path of the value from this type.
+func (r *commentResolver) describeRegistered(t reflect.Type,
+	goPath, rel string,
+	fieldDescriptions map[string]FieldDoc,
+) {
+	if goPath != "" && !r.paths[goPath] {
+		return
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if formatterFor(t) != nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() != "" {
+			fieldDescriptions, _ = registeredDescriptions(t)
+			rel = ""
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+
+			fieldGoPath := joinGoPath(goPath, field.Name)
+			fieldRel := joinGoPath(rel, field.Name)
+
+			for _, env := range r.envs[fieldGoPath] {
+				env.setDoc(fieldDescriptions[fieldRel])
+			}
+
+			r.describeRegistered(field.Type, fieldGoPath, fieldRel, fieldDescriptions)
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		for _, elementGoPath := range r.elements(goPath) {
+			r.describeRegistered(t.Elem(), elementGoPath, rel, fieldDescriptions)
+		}
+	default:
+	}
+}
+
+// GenerateDescriptions returns the source of a Go file registering the descriptions of the fields of the struct types
+// declared in the package of the given directory, as parsed from their comments. If types are given, only the
+// descriptions of these types are registered. Test files and the files generated by GenerateDescriptions are ignored.
+// If no field is documented, the file only declares the package, so that it still compiles.
+func GenerateDescriptions(dir string, types ...string) ([]byte, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseFiles(token.NewFileSet(), packageFiles(pkg))
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range types {
+		wanted[name] = true
+	}
+
+	var registrations bytes.Buffer
+
+	for _, file := range files {
+		if ast.IsGenerated(file) {
+			continue
+		}
+
+		for _, spec := range structTypeSpecs(file) {
+			if len(wanted) > 0 && !wanted[spec.Name.Name] {
+				continue
+			}
+
+			fieldDescriptions := map[string]FieldDoc{}
+			extractDescriptions(spec.Type.(*ast.StructType), "", fieldDescriptions)
+
+			if len(fieldDescriptions) == 0 {
+				continue
+			}
+
+			writeRegistration(&registrations, spec.Name.Name, fieldDescriptions)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by structviewer-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg.Name)
+
+	if registrations.Len() > 0 {
+		buf.WriteString("\nimport (\n\t\"reflect\"\n\n\t\"github.com/TykTechnologies/structviewer\"\n)\n\n")
+		buf.WriteString("func init() {\n")
+		buf.Write(registrations.Bytes())
+		buf.WriteString("}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// structTypeSpecs returns the declarations of the non-generic struct types of the given file.
+func structTypeSpecs(file *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.TypeParams != nil || typeSpec.Assign.IsValid() {
+				continue
+			}
+
+			if _, ok := typeSpec.Type.(*ast.StructType); ok {
+				specs = append(specs, typeSpec)
+			}
+		}
+	}
+
+	return specs
+}
+
+// extractDescriptions adds the comments of the fields of the given struct type, and of the fields of its inline
+// structs, to fieldDescriptions. rel is the Go path of the struct from the named type declaring it.
+func extractDescriptions(s *ast.StructType, rel string, fieldDescriptions map[string]FieldDoc) {
+	for _, field := range s.Fields.List {
+		doc := fieldDoc(field)
+
+		for _, name := range fieldNames(field) {
+			fieldRel := joinGoPath(rel, name)
+
+			if doc != (FieldDoc{}) {
+				fieldDescriptions[fieldRel] = doc
+			}
+
+			if inline, ok := inlineStruct(field.Type); ok {
+				extractDescriptions(inline, fieldRel, fieldDescriptions)
+			}
+		}
+	}
+}
+
+// inlineStruct returns the inline struct type of the given type expression, including the elements of slices, arrays
+// and maps, if any.
+func inlineStruct(expr ast.Expr) (*ast.StructType, bool) {
+	for {
+		switch t := unwrapStarExpr(expr).(type) {
+		case *ast.StructType:
+			return t, true
+		case *ast.ArrayType:
+			expr = t.Elt
+		case *ast.MapType:
+			expr = t.Value
+		default:
+			return nil, false
+		}
+	}
+}
+
+// writeRegistration writes the registration of the given field descriptions of the given type.
+func writeRegistration(buf *bytes.Buffer, typeName string, fieldDescriptions map[string]FieldDoc) {
+	keys := make([]string, 0, len(fieldDescriptions))
+	for key := range fieldDescriptions {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	fmt.Fprintf(buf, "\tstructviewer.RegisterFieldDocs(reflect.TypeOf((*%s)(nil)).Elem(), ", typeName)
+	buf.WriteString("map[string]structviewer.FieldDoc{\n")
+
+	for _, key := range keys {
+		var parts []string
+
+		if doc := fieldDescriptions[key].Doc; doc != "" {
+			parts = append(parts, "Doc: "+strconv.Quote(doc))
+		}
+
+		if comment := fieldDescriptions[key].Comment; comment != "" {
+			parts = append(parts, "Comment: "+strconv.Quote(comment))
+		}
+
+		fmt.Fprintf(buf, "\t\t%s: {%s},\n", strconv.Quote(key), strings.Join(parts, ", "))
+	}
+
+	buf.WriteString("\t})\n")
+}
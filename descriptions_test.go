@@ -0,0 +1,185 @@
+package structviewer
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/structviewer/internal/testconfig"
+)
+
+type testDescribedConfig struct {
+	DescribedBase
+
+	Port int `json:"port"`
+	Data struct {
+		Addr string `json:"addr"`
+	} `json:"data"`
+	Items []testDescribedItem `json:"items"`
+}
+
+// DescribedBase stands for an exported struct embedded into the configuration.
+type DescribedBase struct {
+	Name string `json:"name"`
+}
+
+type testDescribedItem struct {
+	ID int `json:"id"`
+}
+
+func TestRegisterDescriptions(t *testing.T) {
+	RegisterFieldDocs(reflect.TypeOf(testDescribedConfig{}), map[string]FieldDoc{
+		"Port":      {Doc: "Port is the listening port."},
+		"Data.Addr": {Comment: "Addr is the address of the data store."},
+	})
+	RegisterDescriptions(reflect.TypeOf(DescribedBase{}), map[string]string{
+		"Name": "Name is the service name.",
+	})
+	RegisterFieldDocs(reflect.TypeOf(testDescribedItem{}), map[string]FieldDoc{
+		"ID": {Doc: "ID is the item identifier."},
+	})
+
+	viewer, err := New(&Config{
+		Object:        testDescribedConfig{Items: []testDescribedItem{{ID: 1}}},
+		Path:          "./missing.go",
+		ParseComments: true,
+	}, "APP_")
+	assert.NoError(t, err, "registered descriptions must not require the sources")
+
+	tcs := []struct {
+		configField         string
+		expectedDescription string
+	}{
+		{configField: "name", expectedDescription: "Name is the service name."},
+		{configField: "port", expectedDescription: "Port is the listening port."},
+		{configField: "data.addr", expectedDescription: "Addr is the address of the data store."},
+		{configField: "items.0.id", expectedDescription: "ID is the item identifier."},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDescription, viewer.EnvNotation(tc.configField).Description)
+		})
+	}
+
+	_, err = New(&Config{Object: struct{ Port int }{}, Path: "./missing.go", ParseComments: true}, "APP_")
+	assert.Error(t, err, "types without registered descriptions require their sources")
+
+	_, err = New(&Config{Object: testDescribedConfig{}, Path: "./README.md", ParseComments: true}, "APP_")
+	assert.Error(t, err, "invalid sources must be reported even if descriptions are registered")
+}
+
+func TestGenerateDescriptions(t *testing.T) {
+	src, err := GenerateDescriptions("./internal/testconfig")
+	assert.NoError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err, "generated file must be valid Go")
+	assert.Equal(t, "testconfig", file.Name.Name)
+
+	generated := string(src)
+	assert.Contains(t, generated, "// Code generated by structviewer-gen. DO NOT EDIT.")
+	assert.Contains(t, generated, "structviewer.RegisterFieldDocs(reflect.TypeOf((*Upstream)(nil)).Elem(), "+
+		`map[string]structviewer.FieldDoc{
+		"URL": {Doc: "URL is the URL of the upstream."},
+	})`)
+	assert.Contains(t, generated, `"PoolSize": {Doc: "PoolSize is the size of the connection pool.", `+
+		`Comment: "size of the pool"},`)
+
+	src, err = GenerateDescriptions("./internal/testconfig", "Base")
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "(*Base)(nil)")
+	assert.NotContains(t, string(src), "(*Storage)(nil)")
+
+	src, err = GenerateDescriptions("./internal/testconfig", "Missing")
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by structviewer-gen. DO NOT EDIT.\n\npackage testconfig\n", string(src),
+		"files without registration must not import unused packages")
+
+	_, err = GenerateDescriptions("./missing")
+	assert.Error(t, err)
+}
+
+func TestDescriptionSources(t *testing.T) {
+	tcs := []struct {
+		testName     string
+		givenSources []DescriptionSource
+		expected     map[string]string
+	}{
+		{
+			testName: "default",
+			expected: map[string]string{
+				"storage.addr":      "Addr is the address of the storage.",
+				"storage.timeout":   "Timeout of the storage operations, in seconds.",
+				"storage.pool_size": "Maximum number of connections.",
+				"storage.retries":   "Retries, spaced by one second.",
+			},
+		},
+		{
+			testName:     "comments over tags",
+			givenSources: []DescriptionSource{LineCommentDescriptionSource, DocDescriptionSource, TagDescriptionSource},
+			expected: map[string]string{
+				"storage.addr":      "Addr is the address of the storage.",
+				"storage.timeout":   "Timeout of the storage operations, in seconds.",
+				"storage.pool_size": "size of the pool",
+				"storage.retries": "Retries is the number of retries of the failed operations.\n\n" +
+					"Retries are spaced by one second.",
+			},
+		},
+		{
+			testName:     "tags only",
+			givenSources: []DescriptionSource{TagDescriptionSource},
+			expected: map[string]string{
+				"storage.addr":      "",
+				"storage.timeout":   "",
+				"storage.pool_size": "Maximum number of connections.",
+				"storage.retries":   "Retries, spaced by one second.",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{
+				Object:             testconfig.Config{},
+				Path:               "./internal/testconfig",
+				ParseComments:      true,
+				DescriptionSources: tc.givenSources,
+			}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			for configField, description := range tc.expected {
+				assert.Equal(t, description, viewer.EnvNotation(configField).Description, configField)
+			}
+		})
+	}
+
+	_, err := New(&Config{Object: testconfig.Config{}, DescriptionSources: []DescriptionSource{"godoc"}}, "APP_")
+	assert.True(t, errors.Is(err, ErrUnknownDescriptionSource))
+}
+
+func TestTagDescriptionWithoutComments(t *testing.T) {
+	viewer, err := New(&Config{Object: struct {
+		Port     int    `json:"port" desc:"Listening port."`
+		Secret   string `json:"secret" structviewer:"obfuscate,desc=Secret, never logged"`
+		Token    string `json:"token" structviewer:"desc=API token, rotated monthly,obfuscate,mode=presence"`
+		Password string `json:"password" structviewer:"desc=Shared password, never rotated,public"`
+		Key      string `json:"key" structviewer:"desc='Key, public',obfuscate"`
+	}{Secret: "secret", Token: "abc", Password: "password", Key: "key"}, Redaction: &RedactionPolicy{}}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "Listening port.", viewer.EnvNotation("port").Description)
+	assert.Equal(t, "Secret, never logged", viewer.EnvNotation("secret").Description)
+	assert.Equal(t, "*REDACTED*", viewer.EnvNotation("secret").Value)
+	assert.Equal(t, "API token, rotated monthly", viewer.EnvNotation("token").Description)
+	assert.Equal(t, "set", viewer.EnvNotation("token").Value, "options following desc must be honoured")
+	assert.NotContains(t, viewer.ParseEnvs(), "APP_TOKEN=abc")
+	assert.Equal(t, "Shared password, never rotated", viewer.EnvNotation("password").Description)
+	assert.Equal(t, "password", viewer.EnvNotation("password").Value, "public following desc must be honoured")
+	assert.Equal(t, "Key, public", viewer.EnvNotation("key").Description)
+	assert.Equal(t, "*REDACTED*", viewer.EnvNotation("key").Value)
+}
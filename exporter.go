@@ -3,6 +3,7 @@ package structviewer
 import (
 	"encoding/json"
 	"net/http"
+	"reflect"
 )
 
 const (
@@ -14,6 +15,8 @@ const (
 
 // ConfigHandler exposes the configuration struct as JSON fields
 func (v *Viewer) ConfigHandler(rw http.ResponseWriter, r *http.Request) {
+	v = v.viewFor(r)
+
 	if v.config == nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
@@ -23,7 +26,7 @@ func (v *Viewer) ConfigHandler(rw http.ResponseWriter, r *http.Request) {
 
 	if configField := r.URL.Query().Get(JSONQueryKey); configField != "" {
 		response := v.EnvNotation(configField)
-		if response.Value == nil {
+		if response.Env == "" {
 			rw.WriteHeader(http.StatusNotFound)
 
 			err := json.NewEncoder(rw).Encode(map[string]string{
@@ -45,15 +48,18 @@ func (v *Viewer) ConfigHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := json.NewEncoder(rw).Encode(v.config)
+	err := json.NewEncoder(rw).Encode(v.formatConfig(reflect.ValueOf(v.config), ""))
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
 
-// DetailedConfigHandler exposes the detailed configuration struct as JSON fields
+// DetailedConfigHandler exposes the detailed configuration struct as JSON fields. With '?modified=true', only the
+// fields which differ from their default value are exposed.
 func (v *Viewer) DetailedConfigHandler(rw http.ResponseWriter, r *http.Request) {
+	v = v.viewFor(r)
+
 	if v.configMap == nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
@@ -63,7 +69,7 @@ func (v *Viewer) DetailedConfigHandler(rw http.ResponseWriter, r *http.Request)
 
 	if configField := r.URL.Query().Get(JSONQueryKey); configField != "" {
 		response := v.EnvNotation(configField)
-		if response.Value == nil {
+		if response.Env == "" {
 			rw.WriteHeader(http.StatusNotFound)
 
 			err := json.NewEncoder(rw).Encode(map[string]string{
@@ -84,15 +90,23 @@ func (v *Viewer) DetailedConfigHandler(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := json.NewEncoder(rw).Encode(v.configMap)
+	configMap := v.configMap
+	if modifiedOnly(r) {
+		configMap = filterEnvs(configMap, Modified)
+	}
+
+	err := json.NewEncoder(rw).Encode(configMap)
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
 
-// EnvsHandler expose the environment variables of the configuration struct
+// EnvsHandler expose the environment variables of the configuration struct. With '?modified=true', only the fields
+// which differ from their default value are exposed.
 func (v *Viewer) EnvsHandler(rw http.ResponseWriter, r *http.Request) {
+	v = v.viewFor(r)
+
 	if v.envs == nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
@@ -102,7 +116,7 @@ func (v *Viewer) EnvsHandler(rw http.ResponseWriter, r *http.Request) {
 
 	if env := r.URL.Query().Get(EnvQueryKey); env != "" {
 		response := v.JSONNotation(env)
-		if response.Value == nil {
+		if response.Env == "" {
 			rw.WriteHeader(http.StatusNotFound)
 
 			err := json.NewEncoder(rw).Encode(map[string]string{
@@ -123,7 +137,73 @@ func (v *Viewer) EnvsHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := json.NewEncoder(rw).Encode(v.ParseEnvs())
+	var filters []EnvFilter
+	if modifiedOnly(r) {
+		filters = append(filters, Modified)
+	}
+
+	err := json.NewEncoder(rw).Encode(v.ParseEnvs(filters...))
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// RedactionReportHandler exposes the redaction report of the configuration struct
+func (v *Viewer) RedactionReportHandler(rw http.ResponseWriter, r *http.Request) {
+	v = v.viewFor(r)
+
+	if v.envs == nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(v.RedactionReport())
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// UnknownEnvsHandler exposes the unknown environment variables of Config.Environ, or of the process if it is not
+// given. The suggestions only name the environment variables of the view of the caller.
+func (v *Viewer) UnknownEnvsHandler(rw http.ResponseWriter, r *http.Request) {
+	v = v.viewFor(r)
+
+	if v.envs == nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-type", "application/json")
+
+	err := json.NewEncoder(rw).Encode(v.UnknownEnvs(v.environList()))
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// ValidateHandler exposes the violations of the validation constraints of the configuration struct. It responds with
+// http.StatusServiceUnavailable if there are violations, so that it can be used as a startup probe.
+func (v *Viewer) ValidateHandler(rw http.ResponseWriter, r *http.Request) {
+	v = v.viewFor(r)
+
+	if v.envs == nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-type", "application/json")
+
+	violations := v.Validate()
+	if len(violations) > 0 {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	err := json.NewEncoder(rw).Encode(violations)
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -50,7 +49,8 @@ func toJSON(t *testing.T, data interface{}) string {
 
 // complexStructToMap returns a map representation of the complexStruct.
 func complexStructToMap() map[string]*EnvVar {
-	envs := parseEnvs(complexStruct, "TYK_", "")
+	v := Viewer{naming: DefaultNaming, tagKeys: []string{"json"}}
+	envs := v.parseEnvs(complexStruct, "TYK_", "", "")
 	configMap := parseConfig(envs)
 
 	return configMap
@@ -170,6 +170,7 @@ func TestDetailedConfigHandler(t *testing.T) {
 					"config_field": "field_name",
 					"env":          "TYK_FIELDNAME",
 					"value":        "field_value",
+					"type":         "string",
 					"obfuscated":   false,
 				},
 			}),
@@ -188,6 +189,7 @@ func TestDetailedConfigHandler(t *testing.T) {
 			expectedJSONOutput: toJSON(t, EnvVar{
 				Env:         "TYK_NAME",
 				Value:       complexStruct.Name,
+				Type:        "string",
 				ConfigField: "name",
 				Obfuscated:  getPointerBool(false),
 			}),
@@ -199,7 +201,8 @@ func TestDetailedConfigHandler(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedJSONOutput: toJSON(t, EnvVar{
 				Env:         "TYK_DATA_OBJECT1",
-				Value:       strconv.Itoa(complexStruct.Data.Object1),
+				Value:       complexStruct.Data.Object1,
+				Type:        "int",
 				ConfigField: "data.object_1",
 				Obfuscated:  getPointerBool(false),
 			}),
@@ -299,8 +302,8 @@ func TestEnvsHandler(t *testing.T) {
 				`["NAME=name_value",` +
 					`"DATA_OBJECT1=1",` +
 					`"DATA_OBJECT2=true",` +
-					`"METADATA_ID=99",` +
-					`"METADATA_VALUE=key99",` +
+					`"METADATA_KEY99_ID=99",` +
+					`"METADATA_KEY99_VALUE=key99",` +
 					`"OMITTEDVALUE=''"]`,
 			),
 		},
@@ -313,6 +316,7 @@ func TestEnvsHandler(t *testing.T) {
 			expectedJSONOutput: toJSON(t, EnvVar{
 				Env:         "TYK_NAME",
 				Value:       complexStruct.Name,
+				Type:        "string",
 				ConfigField: "name",
 				Obfuscated:  getPointerBool(false),
 			}),
@@ -325,7 +329,8 @@ func TestEnvsHandler(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedJSONOutput: toJSON(t, EnvVar{
 				Env:         "TYK_DATA_OBJECT1",
-				Value:       strconv.Itoa(complexStruct.Data.Object1),
+				Value:       complexStruct.Data.Object1,
+				Type:        "int",
 				ConfigField: "data.object_1",
 				Obfuscated:  getPointerBool(false),
 			}),
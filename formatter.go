@@ -0,0 +1,392 @@
+package structviewer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ValueFormatter renders a value in the form accepted by configuration loaders, e.g. '1m30s' for a time.Duration.
+// The given value is never a pointer.
+type ValueFormatter func(v reflect.Value) string
+
+var (
+	formattersMu sync.RWMutex
+	// formatters is the registry of value formatters, indexed by the type they render.
+	formatters = map[reflect.Type]ValueFormatter{
+		reflect.TypeOf(time.Duration(0)): func(v reflect.Value) string {
+			return time.Duration(v.Int()).String()
+		},
+		reflect.TypeOf(time.Time{}): func(v reflect.Value) string {
+			return addressable(v).Interface().(*time.Time).Format(time.RFC3339Nano)
+		},
+		reflect.TypeOf(net.IP{}): func(v reflect.Value) string {
+			if v.Len() == 0 {
+				return ""
+			}
+
+			return net.IP(v.Bytes()).String()
+		},
+		reflect.TypeOf(net.IPNet{}): func(v reflect.Value) string {
+			return addressable(v).Interface().(*net.IPNet).String()
+		},
+		reflect.TypeOf(url.URL{}): func(v reflect.Value) string {
+			return addressable(v).Interface().(*url.URL).String()
+		},
+		reflect.TypeOf(regexp.Regexp{}): func(v reflect.Value) string {
+			return addressable(v).Interface().(*regexp.Regexp).String()
+		},
+	}
+
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// RegisterFormatter registers the formatter used to render the values of the given type, replacing the existing one
+// if any. Values of registered types are considered as leaf values: structs are not walked and slices are not
+// expanded.
+func RegisterFormatter(t reflect.Type, formatter ValueFormatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+
+	formatters[t] = formatter
+}
+
+// formatterFor returns the formatter of the given type. Registered formatters take precedence over the
+// encoding.TextMarshaler and fmt.Stringer implementations. Structs implementing fmt.Stringer are still walked, since
+// configuration structs often implement it for logging purposes.
+func formatterFor(t reflect.Type) ValueFormatter {
+	if t.Kind() == reflect.Interface {
+		// Interfaces are formatted according to their concrete value.
+		return nil
+	}
+
+	formattersMu.RLock()
+	formatter, ok := formatters[t]
+	formattersMu.RUnlock()
+
+	switch {
+	case ok:
+		return formatter
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return formatTextMarshaler
+	case t.Kind() != reflect.Struct &&
+		(t.Implements(stringerType) || reflect.PointerTo(t).Implements(stringerType)):
+		return formatStringer
+	default:
+		return nil
+	}
+}
+
+// isRegistered reports whether a formatter is registered for the given type.
+func isRegistered(t reflect.Type) bool {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	_, ok := formatters[t]
+
+	return ok
+}
+
+// isFormatted reports whether the given value is rendered by a formatter.
+func isFormatted(v reflect.Value) bool {
+	return v.IsValid() && formatterFor(v.Type()) != nil
+}
+
+func formatTextMarshaler(v reflect.Value) string {
+	text, err := addressable(v).Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return ""
+	}
+
+	return string(text)
+}
+
+func formatStringer(v reflect.Value) string {
+	return addressable(v).Interface().(fmt.Stringer).String()
+}
+
+// addressable returns a pointer to a copy of the given value, so that methods with pointer receivers can be called.
+func addressable(v reflect.Value) reflect.Value {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+
+	return ptr
+}
+
+// formatConfig returns a representation of the given value that encoding/json renders with the registered
+// formatters and the masks of the obfuscated values, so that ConfigHandler renders the values as ParseEnvs does.
+// Values holding neither formatted nor masked values are returned as is, and values implementing json.Marshaler are
+// rendered by their MarshalJSON method. goPath is the Go path of the value.
+func (v *Viewer) formatConfig(value reflect.Value, goPath string) interface{} {
+	if masked, ok := v.masks[goPath]; ok && goPath != "" {
+		return masked
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return nil
+	}
+
+	if isJSONMarshaler(value.Type()) {
+		// The value is part of the obfuscated configuration, so its masked values are rendered as masked.
+		return addressable(value).Interface()
+	}
+
+	if !v.hasMasks(goPath) && !containsFormatted(value.Type(), map[reflect.Type]bool{}) {
+		if value.Kind() == reflect.Struct || value.Kind() == reflect.Array {
+			// The fields and the elements are addressable, as in the configuration, so that the json.Marshaler
+			// methods with pointer receivers are called.
+			return addressable(value).Interface()
+		}
+
+		return value.Interface()
+	}
+
+	if formatter := formatterFor(value.Type()); formatter != nil {
+		return formatter(value)
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return v.formatStruct(value, goPath)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+
+		elems := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elems = append(elems, v.formatConfig(value.Index(i), joinGoPath(goPath, strconv.Itoa(i))))
+		}
+
+		return elems
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+
+		m := make(map[string]interface{}, value.Len())
+		for _, key := range value.MapKeys() {
+			keyStr := fmt.Sprint(key.Interface())
+			m[keyStr] = v.formatConfig(value.MapIndex(key), joinGoPath(goPath, keyStr))
+		}
+
+		return m
+	default:
+		return value.Interface()
+	}
+}
+
+// containsFormatted reports whether the values of the given type are, or contain, values rendered by a formatter.
+func containsFormatted(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+
+	visited[t] = true
+
+	if formatterFor(t) != nil {
+		return !isJSONMarshaler(t)
+	}
+
+	if isJSONMarshaler(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		// The concrete value of interfaces is only known at runtime, so they may contain formatted values.
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsFormatted(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if containsFormatted(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isJSONMarshaler reports whether the values of the given type are rendered by their json.Marshaler implementation,
+// i.e. no formatter is registered for the type.
+func isJSONMarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface || isRegistered(t) {
+		return false
+	}
+
+	return t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)
+}
+
+// jsonMember is a member of a jsonObject.
+type jsonMember struct {
+	name  string
+	value interface{}
+}
+
+// jsonObject is a JSON object which keeps the order of its members.
+type jsonObject []jsonMember
+
+// MarshalJSON implements json.Marshaler.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(member.name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// formatStruct renders the given struct following the encoding/json rules for field names, 'omitempty' and
+// embedded structs. Fields of embedded structs are shadowed by the fields of the outer struct.
+func (v *Viewer) formatStruct(value reflect.Value, goPath string) jsonObject {
+	typ := value.Type()
+	direct := map[string]bool{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		if name, ok := jsonFieldName(typ.Field(i)); ok && !isInlined(typ.Field(i)) {
+			direct[name] = true
+		}
+	}
+
+	var obj jsonObject
+
+	seen := map[string]bool{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := value.Field(i)
+		fieldGoPath := joinGoPath(goPath, field.Name)
+
+		if isInlined(field) {
+			embedded := reflect.Indirect(fieldValue)
+			if !embedded.IsValid() {
+				continue
+			}
+
+			for _, member := range v.formatStruct(embedded, fieldGoPath) {
+				if !direct[member.name] && !seen[member.name] {
+					seen[member.name] = true
+					obj = append(obj, member)
+				}
+			}
+
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		_, options := parseTag(field.Tag.Get("json"))
+		tagOptions := Field{TagOptions: options}
+
+		_, masked := v.masks[fieldGoPath]
+		if tagOptions.HasTagOption("omitempty") && !masked && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		fieldJSON := v.formatConfig(fieldValue, fieldGoPath)
+		if tagOptions.HasTagOption("string") && fieldJSON != nil && !masked {
+			fieldJSON = fmt.Sprint(fieldJSON)
+		}
+
+		seen[name] = true
+		obj = append(obj, jsonMember{name: name, value: fieldJSON})
+	}
+
+	return obj
+}
+
+// jsonFieldName returns the name of the given field in JSON, and false if the field is not rendered by
+// encoding/json.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || !field.IsExported() {
+		return "", false
+	}
+
+	name, _ := parseTag(tag)
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// isInlined reports whether the fields of the given embedded struct are promoted to its parent by encoding/json.
+// Unexported embedded structs are ignored, since their fields cannot be read through reflection.
+func isInlined(field reflect.StructField) bool {
+	if !field.Anonymous || !field.IsExported() {
+		return false
+	}
+
+	name, _ := parseTag(field.Tag.Get("json"))
+	if name != "" || field.Tag.Get("json") == "-" {
+		return false
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+// isEmptyValue reports whether the given value is empty according to the encoding/json 'omitempty' option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
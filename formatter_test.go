@@ -0,0 +1,243 @@
+package structviewer
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLevel int
+
+func (l testLevel) String() string {
+	return [...]string{"debug", "info"}[l]
+}
+
+type testHexColor struct {
+	R, G, B uint8
+}
+
+func (c testHexColor) MarshalText() ([]byte, error) {
+	return []byte{'#', "0123456789abcdef"[c.R>>4], "0123456789abcdef"[c.R&0xf]}, nil
+}
+
+type testStringerStruct struct {
+	Addr string `json:"addr"`
+}
+
+func (testStringerStruct) String() string {
+	return "stringer"
+}
+
+func TestFormattedValues(t *testing.T) {
+	_, network, err := net.ParseCIDR("10.0.0.0/8")
+	assert.NoError(t, err)
+
+	endpoint, err := url.Parse("https://example.com/path")
+	assert.NoError(t, err)
+
+	config := struct {
+		Timeout  time.Duration      `json:"timeout"`
+		Started  time.Time          `json:"started"`
+		IP       net.IP             `json:"ip"`
+		Network  net.IPNet          `json:"network"`
+		Endpoint *url.URL           `json:"endpoint"`
+		Pattern  *regexp.Regexp     `json:"pattern"`
+		Level    testLevel          `json:"level"`
+		Color    testHexColor       `json:"color"`
+		Server   testStringerStruct `json:"server"`
+		Retries  []time.Duration    `json:"retries"`
+		Unset    *url.URL           `json:"unset,omitempty"`
+	}{
+		Timeout:  90 * time.Second,
+		Started:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		IP:       net.ParseIP("127.0.0.1"),
+		Network:  *network,
+		Endpoint: endpoint,
+		Pattern:  regexp.MustCompile("^a+$"),
+		Level:    1,
+		Color:    testHexColor{R: 0xab},
+		Server:   testStringerStruct{Addr: "localhost"},
+		Retries:  []time.Duration{time.Second, time.Minute},
+	}
+
+	viewer, err := New(&Config{Object: config}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.ElementsMatch(t, []string{
+		"APP_TIMEOUT=1m30s",
+		"APP_STARTED=2024-01-02T03:04:05Z",
+		"APP_IP=127.0.0.1",
+		"APP_NETWORK=10.0.0.0/8",
+		"APP_ENDPOINT=https://example.com/path",
+		"APP_PATTERN=^a+$",
+		"APP_LEVEL=info",
+		"APP_COLOR=#ab",
+		"APP_SERVER_ADDR=localhost",
+		"APP_RETRIES_0=1s",
+		"APP_RETRIES_1=1m0s",
+		"APP_UNSET=''",
+	}, viewer.ParseEnvs())
+
+	tcs := []struct {
+		configField   string
+		expectedValue interface{}
+		expectedType  string
+	}{
+		{configField: "timeout", expectedValue: "1m30s", expectedType: "duration"},
+		{configField: "started", expectedValue: "2024-01-02T03:04:05Z", expectedType: "time.Time"},
+		{configField: "ip", expectedValue: "127.0.0.1", expectedType: "net.IP"},
+		{configField: "network", expectedValue: "10.0.0.0/8", expectedType: "net.IPNet"},
+		{configField: "endpoint", expectedValue: "https://example.com/path", expectedType: "url.URL"},
+		{configField: "pattern", expectedValue: "^a+$", expectedType: "regexp.Regexp"},
+		{configField: "unset", expectedValue: nil, expectedType: "url.URL"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			envVar := viewer.EnvNotation(tc.configField)
+			assert.Equal(t, tc.expectedValue, envVar.Value)
+			assert.Equal(t, tc.expectedType, envVar.Type)
+		})
+	}
+
+	t.Run("config handler", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(viewer.ConfigHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"timeout": "1m30s",
+			"started": "2024-01-02T03:04:05Z",
+			"ip": "127.0.0.1",
+			"network": "10.0.0.0/8",
+			"endpoint": "https://example.com/path",
+			"pattern": "^a+$",
+			"level": "info",
+			"color": "#ab",
+			"server": {"addr": "localhost"},
+			"retries": ["1s", "1m0s"]
+		}`, rr.Body.String())
+	})
+}
+
+func TestFormattedInterfaceConfigHandler(t *testing.T) {
+	config := struct {
+		Timeout interface{} `json:"timeout"`
+		Server  interface{} `json:"server"`
+		Nil     interface{} `json:"nil"`
+	}{
+		Timeout: time.Minute,
+		Server:  &struct{ Retries []time.Duration }{Retries: []time.Duration{time.Second}},
+	}
+
+	viewer, err := New(&Config{Object: config}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	req, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(viewer.ConfigHandler).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"timeout": "1m0s", "server": {"Retries": ["1s"]}, "nil": null}`, rr.Body.String())
+}
+
+type testMarshalerMap map[string]int
+
+func (testMarshalerMap) MarshalJSON() ([]byte, error) {
+	return []byte(`"custom"`), nil
+}
+
+type testMarshalerStruct struct {
+	Addr string
+}
+
+func (s *testMarshalerStruct) MarshalJSON() ([]byte, error) {
+	return json.Marshal("addr=" + s.Addr)
+}
+
+func TestJSONMarshalerConfigHandler(t *testing.T) {
+	config := struct {
+		M       testMarshalerMap    `json:"m"`
+		S       testMarshalerStruct `json:"s"`
+		Timeout time.Duration       `json:"timeout"`
+		Token   string              `json:"token" structviewer:"obfuscate"`
+		Inner   struct {
+			S testMarshalerStruct `json:"s"`
+		} `json:"inner"`
+	}{
+		M:       testMarshalerMap{"A": 1, "B": 2},
+		S:       testMarshalerStruct{Addr: "localhost"},
+		Timeout: time.Second,
+		Token:   "secret",
+	}
+	config.Inner.S.Addr = "remote"
+
+	viewer, err := New(&Config{Object: config}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(viewer.ConfigHandler).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"m": "custom",
+		"s": "addr=localhost",
+		"timeout": "1s",
+		"token": "*REDACTED*",
+		"inner": {"s": "addr=remote"}
+	}`, rr.Body.String())
+}
+
+func TestFormatStructEncodingRules(t *testing.T) {
+	type Embedded struct {
+		Name    string        `json:"name"`
+		Timeout time.Duration `json:"timeout"`
+	}
+
+	config := struct {
+		Embedded
+		Name    string        `json:"name"`
+		Omitted time.Duration `json:"omitted,omitempty"`
+		Ignored time.Duration `json:"-"`
+		Port    int           `json:"port,string"`
+		hidden  time.Duration
+	}{
+		Embedded: Embedded{Name: "inner", Timeout: time.Second},
+		Name:     "outer",
+		Port:     8080,
+		hidden:   time.Hour,
+	}
+
+	rendered, err := (&Viewer{}).formatStruct(reflect.ValueOf(config), "").MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"outer","timeout":"1s","port":"8080"}`, string(rendered))
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	type secretName string
+
+	RegisterFormatter(reflect.TypeOf(secretName("")), func(v reflect.Value) string {
+		return strings.ToUpper(v.String())
+	})
+
+	viewer, err := New(&Config{Object: struct {
+		Name secretName `json:"name"`
+	}{Name: "value"}}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, []string{"NAME=VALUE"}, viewer.ParseEnvs())
+}
@@ -2,10 +2,7 @@ module github.com/TykTechnologies/structviewer
 
 go 1.22.6
 
-require (
-	github.com/fatih/structs v1.1.0
-	github.com/stretchr/testify v1.8.0
-)
+require github.com/stretchr/testify v1.8.0
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
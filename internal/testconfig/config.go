@@ -0,0 +1,35 @@
+// Package testconfig is a configuration structure spread across several files and packages, used to test the
+// comment parser.
+package testconfig
+
+import (
+	ratelimits "github.com/TykTechnologies/structviewer/internal/testconfig/limits"
+)
+
+// Config is the root configuration structure.
+type Config struct {
+	Base
+
+	// ListenPort is the port the server listens on.
+	ListenPort int `json:"listen_port"`
+	// Storage is the storage configuration.
+	Storage Storage `json:"storage"`
+	// Limits are the rate limits of the server.
+	Limits *ratelimits.Limits `json:"limits"`
+	// Upstreams are the upstreams of the server, indexed by name.
+	Upstreams map[string]Upstream `json:"upstreams"`
+}
+
+// Base is the configuration shared by the services.
+type Base struct {
+	// Name is the name of the service.
+	Name string `json:"name"`
+}
+
+// Metrics is the configuration of another service, which must not be used to describe Config.
+type Metrics struct {
+	// ListenPort is the port the metrics server listens on.
+	ListenPort int `json:"listen_port"`
+	// Enabled enables the metrics.
+	Enabled bool `json:"enabled"`
+}
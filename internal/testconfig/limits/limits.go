@@ -0,0 +1,10 @@
+// Package limits is a package of the configuration structure of the testconfig package.
+package limits
+
+// Limits is the configuration of the rate limits.
+type Limits struct {
+	// Rate is the number of requests allowed per second.
+	Rate int `json:"rate"`
+	// Enabled enables the rate limits.
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,23 @@
+package testconfig
+
+// Storage is the configuration of the storage.
+type Storage struct {
+	// Addr is the address of the storage.
+	Addr string `json:"addr"`
+	// Enabled enables the storage.
+	Enabled bool `json:"enabled"`
+	Timeout int  `json:"timeout"` // Timeout of the storage operations, in seconds.
+	// PoolSize is the size of the connection pool.
+	PoolSize int `json:"pool_size" desc:"Maximum number of connections."` // size of the pool
+	// Retries is the number of retries of the failed operations.
+	//
+	// Retries are
+	// spaced by one second.
+	Retries int `json:"retries" structviewer:"desc=Retries, spaced by one second."`
+}
+
+// Upstream is the configuration of an upstream.
+type Upstream struct {
+	// URL is the URL of the upstream.
+	URL string `json:"url"`
+}
@@ -0,0 +1,295 @@
+package structviewer
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NamingStrategy decides how the environment variable and the JSON notations of the configuration fields are
+// generated. The notation of a field is built by joining the segments of its parents, e.g. the environment variable
+// of the 'addr' field of the first element of the 'hosts' slice is built from the 'HOSTS', '0' and 'ADDR' segments.
+//
+// Custom strategies can embed one of the built-in strategies and override only the methods they need:
+//
+//	type lowerCaseKeys struct {
+//		structviewer.NamingStrategy
+//	}
+//
+//	func (lowerCaseKeys) MapKeyEnv(key string) string { return strings.ToLower(key) }
+//
+//	config := &structviewer.Config{Naming: lowerCaseKeys{structviewer.DefaultNaming}}
+type NamingStrategy interface {
+	// Separator returns the separator placed between the segments of environment variables.
+	Separator() string
+	// FieldEnv returns the environment variable segment of the given struct field.
+	FieldEnv(field Field) string
+	// FieldPath returns the JSON notation segment of the given struct field.
+	FieldPath(field Field) string
+	// MapKeyEnv returns the environment variable segment of the given map key.
+	MapKeyEnv(key string) string
+	// MapKeyPath returns the JSON notation segment of the given map key.
+	MapKeyPath(key string) string
+	// IndexEnv returns the environment variable segment of the given slice index.
+	IndexEnv(index int) string
+	// IndexPath returns the JSON notation segment of the given slice index.
+	IndexPath(index int) string
+	// Skip reports whether the given struct field is not exposed.
+	Skip(field Field) bool
+	// Flatten reports whether the fields of the given struct field are promoted to its parent, without adding a
+	// segment for the field itself.
+	Flatten(field Field) bool
+	// ExpandCollections reports whether slices and maps are expanded into an environment variable per element. If
+	// false, they are exposed as a single environment variable, e.g. 'a,b,c' for slices and 'k1:v1,k2:v2' for maps.
+	// Empty slices and byte slices are always exposed as a single environment variable, byte slices as a string.
+	ExpandCollections() bool
+}
+
+// Field describes a struct field of the configuration structure.
+type Field struct {
+	reflect.StructField
+
+	// TagName is the name of the field in the first tag found out of Config.TagKeys, e.g. 'listen_port' for
+	// `json:"listen_port,omitempty"`. It is empty if the field has none of these tags or if the tag has no name.
+	TagName string
+	// TagOptions are the options following the name in the same tag, e.g. 'omitempty', 'inline' or 'squash'.
+	TagOptions []string
+}
+
+// HasTagOption reports whether the tag of the field includes the given option.
+func (f Field) HasTagOption(option string) bool {
+	for _, o := range f.TagOptions {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// name returns the tag name of the field, or its Go name if the tag has no name.
+func (f Field) name() string {
+	if f.TagName != "" {
+		return f.TagName
+	}
+
+	return f.Name
+}
+
+// newField resolves the tag name and options of the given struct field from the first tag found out of tagKeys.
+func newField(field reflect.StructField, tagKeys []string) Field {
+	f := Field{StructField: field}
+
+	for _, key := range tagKeys {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		f.TagName, f.TagOptions = parseTag(tag)
+
+		break
+	}
+
+	return f
+}
+
+// parseTag splits a struct tag value such as 'name,omitempty' into its name and its options. The '-' name is
+// considered as an empty name.
+func parseTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+
+	name := parts[0]
+	if name == "-" {
+		name = ""
+	}
+
+	var options []string
+
+	for _, option := range parts[1:] {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+
+	return name, options
+}
+
+var (
+	// DefaultNaming uses the tag name of the fields, or their name if the tag is missing, removes the underscores and
+	// converts them to upper case. For example, 'listen_port' becomes 'LISTENPORT'. Embedded structs without tag name
+	// are flattened as encoding/json does, as well as the fields tagged with the 'inline' or 'squash' options.
+	DefaultNaming NamingStrategy = defaultNaming{}
+	// EnvconfigNaming generates the same environment variable names as github.com/kelseyhightower/envconfig.
+	// It honours the 'envconfig', 'split_words' and 'ignored' tags, flattens embedded structs and exposes slices and
+	// maps as a single environment variable.
+	EnvconfigNaming NamingStrategy = envconfigNaming{}
+	// ScreamingSnakeNaming converts the tag name of the fields to upper case, preserving its underscores. Fields
+	// without tag name are split into words, e.g. both 'listen_port' and 'ListenPort' become 'LISTEN_PORT'.
+	ScreamingSnakeNaming NamingStrategy = screamingSnakeNaming{}
+	// ViperNaming generates the environment variables read by github.com/spf13/viper when the nested keys are
+	// replaced with a double underscore, e.g. 'database.max_conns' becomes 'DATABASE__MAX_CONNS'.
+	ViperNaming NamingStrategy = viperNaming{}
+)
+
+// DefaultMapKeyFormatter removes the underscores of the given map key and converts it to upper case.
+func DefaultMapKeyFormatter(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, "_", ""))
+}
+
+// defaultNaming is the naming strategy used when no strategy is configured.
+type defaultNaming struct{}
+
+func (defaultNaming) Separator() string {
+	return "_"
+}
+
+func (defaultNaming) FieldEnv(field Field) string {
+	return strings.ToUpper(strings.ReplaceAll(field.name(), "_", ""))
+}
+
+func (defaultNaming) FieldPath(field Field) string {
+	return field.name()
+}
+
+func (defaultNaming) MapKeyEnv(key string) string {
+	return DefaultMapKeyFormatter(key)
+}
+
+func (defaultNaming) MapKeyPath(key string) string {
+	return key
+}
+
+func (defaultNaming) IndexEnv(index int) string {
+	return strconv.Itoa(index)
+}
+
+func (defaultNaming) IndexPath(index int) string {
+	return strconv.Itoa(index)
+}
+
+func (defaultNaming) Skip(Field) bool {
+	return false
+}
+
+func (defaultNaming) Flatten(field Field) bool {
+	return (field.Anonymous && field.TagName == "") || field.HasTagOption("inline") || field.HasTagOption("squash")
+}
+
+func (defaultNaming) ExpandCollections() bool {
+	return true
+}
+
+// envconfigNaming reproduces the naming rules of github.com/kelseyhightower/envconfig.
+type envconfigNaming struct {
+	defaultNaming
+}
+
+var (
+	// gatherRegexp and acronymRegexp are used by envconfig to split camel case field names into words.
+	gatherRegexp  = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+func (envconfigNaming) FieldEnv(field Field) string {
+	key := field.Name
+
+	if isTrue(field.Tag.Get("split_words")) {
+		key = splitWords(key)
+	}
+
+	if alt := field.Tag.Get("envconfig"); alt != "" {
+		key = alt
+	}
+
+	return strings.ToUpper(key)
+}
+
+func (envconfigNaming) MapKeyEnv(key string) string {
+	return strings.ToUpper(key)
+}
+
+func (envconfigNaming) Skip(field Field) bool {
+	return isTrue(field.Tag.Get("ignored"))
+}
+
+func (envconfigNaming) Flatten(field Field) bool {
+	return field.Anonymous
+}
+
+func (envconfigNaming) ExpandCollections() bool {
+	return false
+}
+
+// screamingSnakeNaming keeps the underscores of the tag names.
+type screamingSnakeNaming struct {
+	defaultNaming
+}
+
+func (screamingSnakeNaming) FieldEnv(field Field) string {
+	name := field.TagName
+	if name == "" {
+		name = splitWords(field.Name)
+	}
+
+	return strings.ToUpper(name)
+}
+
+func (screamingSnakeNaming) MapKeyEnv(key string) string {
+	return strings.ToUpper(key)
+}
+
+// viperNaming separates nested keys with a double underscore.
+type viperNaming struct {
+	defaultNaming
+}
+
+func (viperNaming) Separator() string {
+	return "__"
+}
+
+func (viperNaming) FieldEnv(field Field) string {
+	return strings.ToUpper(field.name())
+}
+
+func (viperNaming) MapKeyEnv(key string) string {
+	return strings.ToUpper(key)
+}
+
+// mapKeyNaming overrides the map key notation of a naming strategy with Config.MapKeyFormatter.
+type mapKeyNaming struct {
+	NamingStrategy
+	format func(key string) string
+}
+
+func (n mapKeyNaming) MapKeyEnv(key string) string {
+	return n.format(key)
+}
+
+// splitWords splits the given camel case name into words joined by underscores, e.g. 'AutoSplitVar' becomes
+// 'Auto_Split_Var'.
+func splitWords(name string) string {
+	words := gatherRegexp.FindAllStringSubmatch(name, -1)
+	if len(words) == 0 {
+		return name
+	}
+
+	var parts []string
+
+	for _, word := range words {
+		if m := acronymRegexp.FindStringSubmatch(word[0]); len(m) == 3 {
+			parts = append(parts, m[1], m[2])
+		} else {
+			parts = append(parts, word[0])
+		}
+	}
+
+	return strings.Join(parts, "_")
+}
+
+func isTrue(s string) bool {
+	b, err := strconv.ParseBool(s)
+
+	return err == nil && b
+}
@@ -0,0 +1,351 @@
+package structviewer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// envconfigSpecification is based on the examples documented by github.com/kelseyhightower/envconfig.
+type envconfigSpecification struct {
+	envconfigEmbedded
+
+	Debug                        bool
+	Port                         int
+	User                         string
+	Users                        []string
+	Rate                         float32
+	Timeout                      time.Duration
+	ColorCodes                   map[string]int
+	ManualOverride1              string `envconfig:"manual_override_1"`
+	DefaultVar                   string `default:"foobar"`
+	RequiredVar                  string `required:"true"`
+	IgnoredVar                   string `ignored:"true"`
+	AutoSplitVar                 string `split_words:"true"`
+	RequiredAndAutoSplitVar      string `required:"true" split_words:"true"`
+	MultiWordACRWithAutoSplit    string `split_words:"true"`
+	SomeURL                      string `split_words:"true"`
+	HTTPPort                     int    `split_words:"true"`
+	MultiWordVarWithAlt          string `envconfig:"multi_word_alt" split_words:"true"`
+	NestedSpecification          envconfigNested
+	NestedWithAlt                envconfigNested  `envconfig:"outer"`
+	PointerSpecification         *envconfigNested `split_words:"true"`
+	EmbeddedPointer              *EnvconfigEmbeddedPointer
+	IgnoredNestedSpecification   envconfigNested `ignored:"true"`
+	SplitWordsNestedSpecificaion envconfigNested `split_words:"true"`
+}
+
+type envconfigNested struct {
+	Property            string `envconfig:"inner"`
+	PropertyWithDefault string `default:"fuzzybydefault"`
+}
+
+type envconfigEmbedded struct {
+	Enabled bool
+}
+
+type EnvconfigEmbeddedPointer struct {
+	Ignored string `ignored:"true"`
+}
+
+type envconfigSplitEmbedded struct {
+	*EnvconfigEmbedded
+	EmbeddedPort int `split_words:"true"`
+}
+
+type EnvconfigEmbedded struct {
+	Enabled             bool
+	MultiWordVarWithAlt string `envconfig:"MULTI_WITH_DIFFERENT_ALT"`
+	EmbeddedIgnored     string `ignored:"true"`
+}
+
+func TestEnvconfigNaming(t *testing.T) {
+	tcs := []struct {
+		testName     string
+		given        interface{}
+		expectedEnvs []string
+	}{
+		{
+			testName: "documented specification",
+			given: envconfigSpecification{
+				Debug:      true,
+				Port:       8080,
+				User:       "Kelsey",
+				Users:      []string{"rob", "ken", "robert"},
+				Rate:       0.5,
+				Timeout:    3 * time.Minute,
+				ColorCodes: map[string]int{"red": 1, "green": 2, "blue": 3},
+			},
+			expectedEnvs: []string{
+				"MYAPP_DEBUG=true",
+				"MYAPP_PORT=8080",
+				"MYAPP_USER=Kelsey",
+				"MYAPP_USERS=rob,ken,robert",
+				"MYAPP_RATE=0.5",
+				"MYAPP_TIMEOUT=3m0s",
+				"MYAPP_COLORCODES=blue:3,green:2,red:1",
+				"MYAPP_MANUAL_OVERRIDE_1=''",
+				"MYAPP_DEFAULTVAR=''",
+				"MYAPP_REQUIREDVAR=''",
+				"MYAPP_AUTO_SPLIT_VAR=''",
+				"MYAPP_REQUIRED_AND_AUTO_SPLIT_VAR=''",
+				"MYAPP_MULTI_WORD_ACR_WITH_AUTO_SPLIT=''",
+				"MYAPP_SOME_URL=''",
+				"MYAPP_HTTP_PORT=0",
+				"MYAPP_MULTI_WORD_ALT=''",
+				"MYAPP_NESTEDSPECIFICATION_INNER=''",
+				"MYAPP_NESTEDSPECIFICATION_PROPERTYWITHDEFAULT=''",
+				"MYAPP_OUTER_INNER=''",
+				"MYAPP_OUTER_PROPERTYWITHDEFAULT=''",
+				"MYAPP_POINTER_SPECIFICATION_INNER=''",
+				"MYAPP_POINTER_SPECIFICATION_PROPERTYWITHDEFAULT=''",
+				"MYAPP_SPLIT_WORDS_NESTED_SPECIFICAION_INNER=''",
+				"MYAPP_SPLIT_WORDS_NESTED_SPECIFICAION_PROPERTYWITHDEFAULT=''",
+			},
+		},
+		{
+			testName: "embedded pointer",
+			given: envconfigSplitEmbedded{
+				EnvconfigEmbedded: &EnvconfigEmbedded{Enabled: true},
+				EmbeddedPort:      1,
+			},
+			expectedEnvs: []string{
+				"MYAPP_ENABLED=true",
+				"MYAPP_MULTI_WITH_DIFFERENT_ALT=''",
+				"MYAPP_EMBEDDED_PORT=1",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: tc.given, Naming: EnvconfigNaming}, "MYAPP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.ElementsMatch(t, tc.expectedEnvs, viewer.ParseEnvs())
+		})
+	}
+}
+
+func TestEnvconfigKey(t *testing.T) {
+	tcs := []struct {
+		fieldName string
+		expected  string
+	}{
+		{fieldName: "AutoSplitVar", expected: "AUTO_SPLIT_VAR"},
+		{fieldName: "MultiWordACRWithAutoSplit", expected: "MULTI_WORD_ACR_WITH_AUTO_SPLIT"},
+		{fieldName: "URLAddress", expected: "URL_ADDRESS"},
+		{fieldName: "SomeURL", expected: "SOME_URL"},
+		{fieldName: "ID", expected: "ID"},
+		{fieldName: "Port2", expected: "PORT2"},
+		{fieldName: "lowercase", expected: "LOWERCASE"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.fieldName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, strings.ToUpper(splitWords(tc.fieldName)))
+		})
+	}
+}
+
+type lowerCaseMapKeys struct {
+	NamingStrategy
+}
+
+func (lowerCaseMapKeys) MapKeyEnv(key string) string {
+	return strings.ToLower(key)
+}
+
+func TestNamingStrategies(t *testing.T) {
+	type database struct {
+		MaxConns int `json:"max_conns"`
+	}
+
+	config := struct {
+		ListenPort int                 `json:"listen_port"`
+		LogLevel   string              `json:"log_level"`
+		Database   database            `json:"database"`
+		Hosts      []string            `json:"hosts"`
+		Headers    map[string]string   `json:"headers"`
+		Backends   map[string]database `json:"backends"`
+		HTTPServer string
+	}{
+		ListenPort: 8080,
+		LogLevel:   "debug",
+		Database:   database{MaxConns: 10},
+		Hosts:      []string{"a"},
+		Headers:    map[string]string{"x_api": "1"},
+		Backends:   map[string]database{"main_db": {MaxConns: 5}},
+		HTTPServer: "server",
+	}
+
+	tcs := []struct {
+		testName     string
+		naming       NamingStrategy
+		expectedEnvs []string
+	}{
+		{
+			testName: "default naming",
+			naming:   DefaultNaming,
+			expectedEnvs: []string{
+				"APP_LISTENPORT=8080", "APP_LOGLEVEL=debug", "APP_DATABASE_MAXCONNS=10", "APP_HOSTS_0=a",
+				"APP_HEADERS_XAPI=1", "APP_BACKENDS_MAINDB_MAXCONNS=5", "APP_HTTPSERVER=server",
+			},
+		},
+		{
+			testName: "envconfig naming",
+			naming:   EnvconfigNaming,
+			expectedEnvs: []string{
+				"APP_LISTENPORT=8080", "APP_LOGLEVEL=debug", "APP_DATABASE_MAXCONNS=10", "APP_HOSTS=a",
+				"APP_HEADERS=x_api:1", "APP_BACKENDS=main_db:{5}", "APP_HTTPSERVER=server",
+			},
+		},
+		{
+			testName: "screaming snake naming",
+			naming:   ScreamingSnakeNaming,
+			expectedEnvs: []string{
+				"APP_LISTEN_PORT=8080", "APP_LOG_LEVEL=debug", "APP_DATABASE_MAX_CONNS=10", "APP_HOSTS_0=a",
+				"APP_HEADERS_X_API=1", "APP_BACKENDS_MAIN_DB_MAX_CONNS=5", "APP_HTTP_SERVER=server",
+			},
+		},
+		{
+			testName: "viper naming",
+			naming:   ViperNaming,
+			expectedEnvs: []string{
+				"APP_LISTEN_PORT=8080", "APP_LOG_LEVEL=debug", "APP_DATABASE__MAX_CONNS=10", "APP_HOSTS__0=a",
+				"APP_HEADERS__X_API=1", "APP_BACKENDS__MAIN_DB__MAX_CONNS=5", "APP_HTTPSERVER=server",
+			},
+		},
+		{
+			testName: "custom naming",
+			naming:   lowerCaseMapKeys{ScreamingSnakeNaming},
+			expectedEnvs: []string{
+				"APP_LISTEN_PORT=8080", "APP_LOG_LEVEL=debug", "APP_DATABASE_MAX_CONNS=10", "APP_HOSTS_0=a",
+				"APP_HEADERS_x_api=1", "APP_BACKENDS_main_db_MAX_CONNS=5", "APP_HTTP_SERVER=server",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: config, Naming: tc.naming}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.ElementsMatch(t, tc.expectedEnvs, viewer.ParseEnvs())
+		})
+	}
+}
+
+func TestNamingConfigPath(t *testing.T) {
+	config := struct {
+		Backends map[string]struct {
+			MaxConns int `json:"max_conns"`
+		} `json:"backends"`
+	}{
+		Backends: map[string]struct {
+			MaxConns int `json:"max_conns"`
+		}{"main_db": {MaxConns: 5}},
+	}
+
+	viewer, err := New(&Config{Object: config, Naming: ViperNaming}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	envVar := viewer.EnvNotation("backends.main_db.max_conns")
+	assert.Equal(t, "APP_BACKENDS__MAIN_DB__MAX_CONNS", envVar.Env)
+	assert.Equal(t, "backends.main_db.max_conns", viewer.JSONNotation("APP_BACKENDS__MAIN_DB__MAX_CONNS").ConfigField)
+}
+
+func TestParseTag(t *testing.T) {
+	tcs := []struct {
+		tag             string
+		expectedName    string
+		expectedOptions []string
+	}{
+		{tag: "name", expectedName: "name"},
+		{tag: "name,omitempty", expectedName: "name", expectedOptions: []string{"omitempty"}},
+		{tag: "name,omitempty,string", expectedName: "name", expectedOptions: []string{"omitempty", "string"}},
+		{tag: ",inline", expectedOptions: []string{"inline"}},
+		{tag: ",squash", expectedOptions: []string{"squash"}},
+		{tag: "-"},
+		{tag: ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.tag, func(t *testing.T) {
+			name, options := parseTag(tc.tag)
+			assert.Equal(t, tc.expectedName, name)
+			assert.Equal(t, tc.expectedOptions, options)
+		})
+	}
+}
+
+func TestTagKeys(t *testing.T) {
+	type server struct {
+		ListenPort int `json:"port" yaml:"listen_port" mapstructure:"listen_port"`
+	}
+
+	type common struct {
+		LogLevel string `json:"log_level" yaml:"log_level" mapstructure:"log_level"`
+	}
+
+	config := struct {
+		Server   server `json:"server" yaml:"server"`
+		Common   common `json:"common" yaml:",inline" mapstructure:",squash"`
+		Name     string `json:"name,omitempty" toml:"app_name"`
+		Untagged string
+	}{
+		Server:   server{ListenPort: 8080},
+		Common:   common{LogLevel: "debug"},
+		Name:     "app",
+		Untagged: "value",
+	}
+
+	tcs := []struct {
+		testName       string
+		tagKeys        []string
+		expectedFields map[string]string
+	}{
+		{
+			testName: "default json tag",
+			expectedFields: map[string]string{
+				"server.port":      "SERVER_PORT",
+				"common.log_level": "COMMON_LOGLEVEL",
+				"name":             "NAME",
+				"Untagged":         "UNTAGGED",
+			},
+		},
+		{
+			testName: "yaml tag",
+			tagKeys:  []string{"yaml"},
+			expectedFields: map[string]string{
+				"server.listen_port": "SERVER_LISTENPORT",
+				"log_level":          "LOGLEVEL",
+				"Name":               "NAME",
+				"Untagged":           "UNTAGGED",
+			},
+		},
+		{
+			testName: "mapstructure tag with fallbacks",
+			tagKeys:  []string{"mapstructure", "toml", "json"},
+			expectedFields: map[string]string{
+				"server.listen_port": "SERVER_LISTENPORT",
+				"log_level":          "LOGLEVEL",
+				"app_name":           "APPNAME",
+				"Untagged":           "UNTAGGED",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: config, TagKeys: tc.tagKeys}, "")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			for configField, env := range tc.expectedFields {
+				assert.Equal(t, env, viewer.EnvNotation(configField).Env, "unexpected env of %s", configField)
+			}
+		})
+	}
+}
@@ -3,27 +3,27 @@ package structviewer
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
-	"go/token"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/fatih/structs"
+	"time"
 )
 
-// ParseEnvs parse Viewer config field, generating a string slice of prefix+key:value of each config field
-func (v *Viewer) ParseEnvs() []string {
+// ParseEnvs parse Viewer config field, generating a string slice of prefix+key:value of each config field. If filters
+// are given, only the fields selected by all of them are returned, e.g. ParseEnvs(Modified).
+func (v *Viewer) ParseEnvs(filters ...EnvFilter) []string {
 	var envs []string
 	envVars := v.configMap
 
 	for _, envVar := range envVars {
-		envs = append(envs, generateEnvStrings(envVar)...)
+		envs = append(envs, generateEnvStrings(envVar, filters)...)
 	}
 
 	return envs
 }
 
-func generateEnvStrings(e *EnvVar) []string {
+func generateEnvStrings(e *EnvVar, filters []EnvFilter) []string {
 	var strEnvs []string
 
 	if e.isStruct {
@@ -33,17 +33,24 @@ func generateEnvStrings(e *EnvVar) []string {
 		}
 
 		for _, v := range typedEnv {
-			strEnvs = append(strEnvs, generateEnvStrings(v)...)
+			strEnvs = append(strEnvs, generateEnvStrings(v, filters)...)
 		}
 
 		return strEnvs
 	}
 
-	if e.Value == "" || e.Value == nil {
-		e.Value = `''`
+	for _, filter := range filters {
+		if !filter(e) {
+			return nil
+		}
 	}
 
-	strEnvs = append(strEnvs, fmt.Sprintf("%v=%v", e.Env, e.Value))
+	value := e.envValue
+	if value == "" {
+		value = `''`
+	}
+
+	strEnvs = append(strEnvs, fmt.Sprintf("%v=%v", e.Env, value))
 
 	return strEnvs
 }
@@ -132,29 +139,32 @@ func (v *Viewer) Envs() []*EnvVar {
 	return v.envs
 }
 
-func (v *Viewer) parseComments() error {
-	// If we have already parsed comments, don't parse again.
-	if v.file != nil {
-		return nil
+// parseComments sets the comments of the given EnvVar. The comments are resolved by type, starting from the
+// declaration of the configuration structure, or from its struct type literal if it is anonymous, and following the
+// declarations of the types of its fields.
+func (v *Viewer) parseComments(envs []*EnvVar) error {
+	// If we have already parsed the files, don't parse them again.
+	if v.files == nil {
+		if err := v.loadFiles(); err != nil {
+			return err
+		}
 	}
 
-	astFile, err := parser.ParseFile(token.NewFileSet(), v.confFilePath, nil, parser.ParseComments)
-	if err != nil {
-		return err
-	}
+	rootType := reflect.TypeOf(v.original).Elem()
 
-	v.file = astFile
+	resolver := newCommentResolver(v.files, envs)
 
-	ast.Inspect(v.file, func(n ast.Node) bool {
-		structType, ok := n.(*ast.StructType)
-		if !ok {
-			return true
+	if rootType.Name() == "" {
+		// Anonymous structures have no declaration, so their struct type literal is looked up by its fields.
+		s, file := anonymousStruct(v.files[rootType.PkgPath()], rootType)
+		if s == nil {
+			return fmt.Errorf("no struct type with the fields of the configuration structure in %s", v.confFilePath)
 		}
 
-		v.parseInnerFields(structType)
-
-		return false
-	})
+		resolver.describeType(s, file, rootType.PkgPath(), "")
+	} else if spec, file := resolver.typeSpec(rootType.PkgPath(), rootType.Name()); spec != nil {
+		resolver.describeType(spec.Type, file, rootType.PkgPath(), "")
+	}
 
 	return nil
 }
@@ -168,79 +178,287 @@ func parseConfig(envs []*EnvVar) map[string]*EnvVar {
 	return configMap
 }
 
-func (v *Viewer) parseInnerFields(s *ast.StructType) {
-	for _, structField := range s.Fields.List {
-		comment := structField.Doc.Text()
-		confField := structField.Names[0]
+// unwrapStarExpr returns the underlying type expression of pointer type expressions such as '*struct{...}'.
+func unwrapStarExpr(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
+
+func (v *Viewer) parseEnvs(config interface{}, prefix, configField, goPath string) []*EnvVar {
+	return v.parseFields(indirect(reflect.ValueOf(config)), prefix, configField, goPath, newVisitedValues())
+}
+
+// visitedValues holds the struct types and the addresses of the structs being parsed, from the configuration
+// structure to the current struct, so that recursive values are not expanded endlessly: nil pointers to one of the
+// types, e.g. the 'Next' field of a 'Node' struct, and pointers to one of the structs, e.g. a cyclic list.
+type visitedValues struct {
+	types    map[reflect.Type]bool
+	pointers map[pointerKey]bool
+}
+
+// pointerKey identifies a pointed value by its address and its type, since a struct and its first field share their
+// address.
+type pointerKey struct {
+	addr uintptr
+	t    reflect.Type
+}
+
+func newVisitedValues() *visitedValues {
+	return &visitedValues{types: map[reflect.Type]bool{}, pointers: map[pointerKey]bool{}}
+}
+
+// parseFields generates the EnvVar of the fields of the given struct, whose Go path is goPath.
+func (v *Viewer) parseFields(s reflect.Value,
+	prefix, configField, goPath string,
+	visited *visitedValues,
+) []*EnvVar {
+	var envs []*EnvVar
+
+	if !visited.types[s.Type()] {
+		// Non-nil pointers to the struct types being parsed, e.g. linked lists, are parsed as any other struct.
+		visited.types[s.Type()] = true
+		defer delete(visited.types, s.Type())
+	}
+
+	if s.CanAddr() {
+		key := pointerKey{addr: s.UnsafeAddr(), t: s.Type()}
+		if !visited.pointers[key] {
+			visited.pointers[key] = true
+			defer delete(visited.pointers, key)
+		}
+	}
+
+	configField = ensureConfigFieldEndsWithDot(configField)
 
-		envVar := v.get(confField.Name, v.envs)
-		if comment != "" && envVar != nil {
-			envVar.Description = strings.TrimSpace(comment)
+	fields := v.structFields(s.Type())
+	goNames := map[string]int{}
+
+	for _, field := range fields {
+		goNames[field.Name]++
+	}
+
+	for _, field := range fields {
+		newEnv := v.createEnvVar(field.Field)
+		if goNames[field.Name] > 1 {
+			// Promoted fields sharing their Go name with other fields are identified by their full Go path.
+			newEnv.field = strings.Join(field.goPath, ".")
 		}
 
-		if structType, ok := structField.Type.(*ast.StructType); ok {
-			v.parseInnerFields(structType)
+		newEnv.goPath = joinGoPath(goPath, strings.Join(field.goPath, "."))
+		fieldValue := fieldByIndex(s, field.index)
+		value := indirect(fieldValue)
+		expand := v.naming.ExpandCollections()
+		newEnv.Type = dynamicType(field.Type, value)
+
+		switch masked, isMasked := v.masks[newEnv.goPath]; {
+		case isMasked:
+			newEnv.setMasked(masked, field.Type)
+			newEnv.Env = prefix + newEnv.key
+			newEnv.ConfigField = configField + newEnv.ConfigField
+
+			envs = append(envs, newEnv)
+		case isFormatted(value), isRecursive(fieldValue, visited):
+			handleSimpleField(newEnv, unsetRecursive(fieldValue, visited), prefix, configField, &envs)
+		case value.Kind() == reflect.Struct:
+			v.handleStructField(newEnv, value, prefix, configField, &envs, visited)
+		case value.Kind() == reflect.Map && expand:
+			v.handleMapField(newEnv, value, prefix, configField, &envs, visited)
+		case isSliceOrArray(value) && expand && value.Len() > 0:
+			// Empty collections have no element to expand, so they are exposed as a single environment variable.
+			v.handleSliceField(newEnv, value, prefix, configField, &envs, visited)
+		default:
+			handleSimpleField(newEnv, fieldValue, prefix, configField, &envs)
+
+			if v.hasMasks(newEnv.goPath) {
+				// Collections exposed as a single environment variable include their masked values.
+				newEnv.Obfuscated = getPointerBool(true)
+			}
 		}
 	}
+
+	return envs
 }
 
-func (v *Viewer) get(field string, envs []*EnvVar) *EnvVar {
-	for _, env := range envs {
-		if env.field == field {
-			return env
-		}
+// promotedField is a field of a struct, including the fields promoted from its flattened embedded structs.
+type promotedField struct {
+	Field
 
-		if env.isStruct {
-			val, ok := env.Value.(map[string]*EnvVar)
-			if !ok {
+	// index is the index sequence of the field, as in reflect.Value.FieldByIndex.
+	index []int
+	// goPath is the sequence of Go names of the field, e.g. 'Base.Port' for the Port field of the embedded Base struct.
+	goPath []string
+	// depth is the number of flattened structs the field is promoted from.
+	depth int
+}
+
+// structFields returns the fields of the given struct type, promoting the fields of the structs flattened by the
+// naming strategy, e.g. embedded structs. Conflicts of JSON or environment variable notations are resolved as
+// encoding/json does: the least nested field dominates the others, then a tagged field dominates untagged fields at
+// the same depth. If there is still a conflict, all the conflicting fields are ignored. Unexported embedded structs
+// are ignored, since their fields cannot be read through reflection.
+func (v *Viewer) structFields(t reflect.Type) []promotedField {
+	var fields []promotedField
+
+	var walk func(t reflect.Type, index []int, goPath []string, depth int, visited map[reflect.Type]bool)
+
+	walk = func(t reflect.Type, index []int, goPath []string, depth int, visited map[reflect.Type]bool) {
+		for i := 0; i < t.NumField(); i++ {
+			field := newField(t.Field(i), v.tagKeys)
+			if !field.IsExported() || v.naming.Skip(field) {
 				continue
 			}
 
-			temporarySlice := []*EnvVar{}
-			for _, v := range val {
-				temporarySlice = append(temporarySlice, v)
+			fieldIndex := append(index[:len(index):len(index)], i)
+			fieldGoPath := append(goPath[:len(goPath):len(goPath)], field.Name)
+
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
 			}
 
-			ev := v.get(field, temporarySlice)
-			if ev != nil {
-				return ev
+			if fieldType.Kind() == reflect.Struct && formatterFor(fieldType) == nil && !visited[fieldType] &&
+				v.naming.Flatten(field) {
+				visited[fieldType] = true
+				walk(fieldType, fieldIndex, fieldGoPath, depth+1, visited)
+				delete(visited, fieldType)
+
+				continue
 			}
+
+			fields = append(fields, promotedField{Field: field, index: fieldIndex, goPath: fieldGoPath, depth: depth})
 		}
 	}
 
-	return nil
+	walk(t, nil, nil, 0, map[reflect.Type]bool{t: true})
+
+	return v.dominantFields(fields)
 }
 
-func parseEnvs(config interface{}, prefix, configField string) []*EnvVar {
-	var envs []*EnvVar
-	s := structs.New(config)
+// dominantFields removes the fields shadowed by other fields with the same JSON notation, then the promoted fields
+// shadowed by other fields with the same environment variable notation, since different JSON names can share an
+// environment variable, e.g. 'name' and 'Name'. Fields declared by the struct itself are never dropped because of
+// their environment variable, as encoding/json keeps them.
+func (v *Viewer) dominantFields(fields []promotedField) []promotedField {
+	fields = resolveConflicts(fields, false, func(field promotedField) string {
+		return v.naming.FieldPath(field.Field)
+	})
 
-	for _, field := range s.Fields() {
-		if !field.IsExported() {
+	return resolveConflicts(fields, true, func(field promotedField) string {
+		return v.naming.FieldEnv(field.Field)
+	})
+}
+
+// resolveConflicts returns the fields which dominate the other fields with the same name, as returned by the given
+// function. If onlyPromoted is true, the conflicts between fields declared by the struct itself are left as is.
+func resolveConflicts(fields []promotedField,
+	onlyPromoted bool,
+	name func(field promotedField) string,
+) []promotedField {
+	byName := map[string][]promotedField{}
+	for _, field := range fields {
+		byName[name(field)] = append(byName[name(field)], field)
+	}
+
+	var dominant []promotedField
+
+	for _, field := range fields {
+		conflicting := byName[name(field)]
+		if onlyPromoted && !hasPromoted(conflicting) {
+			dominant = append(dominant, field)
 			continue
 		}
 
-		newEnv := createEnvVar(field)
-		configField = ensureConfigFieldEndsWithDot(configField)
+		winner, ok := dominantField(conflicting)
+		if ok && reflect.DeepEqual(winner.index, field.index) {
+			dominant = append(dominant, field)
+		}
+	}
+
+	return dominant
+}
 
-		switch {
-		case structs.IsStruct(field.Value()):
-			handleStructField(newEnv, field, prefix, configField, &envs)
-		case reflect.ValueOf(field.Value()).Kind() == reflect.Map:
-			handleMapField(newEnv, field, prefix, configField, &envs)
-		default:
-			handleSimpleField(newEnv, field, prefix, configField, &envs)
+// hasPromoted reports whether one of the given fields is promoted from an embedded struct.
+func hasPromoted(fields []promotedField) bool {
+	for _, field := range fields {
+		if field.depth > 0 {
+			return true
 		}
 	}
 
-	return envs
+	return false
+}
+
+// dominantField returns the field which dominates the given fields with the same name, and false if there is none.
+func dominantField(fields []promotedField) (promotedField, bool) {
+	if len(fields) == 1 {
+		return fields[0], true
+	}
+
+	minDepth := fields[0].depth
+	for _, field := range fields[1:] {
+		if field.depth < minDepth {
+			minDepth = field.depth
+		}
+	}
+
+	var candidates []promotedField
+
+	for _, field := range fields {
+		if field.depth == minDepth {
+			candidates = append(candidates, field)
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	var tagged []promotedField
+
+	for _, field := range candidates {
+		if field.TagName != "" {
+			tagged = append(tagged, field)
+		}
+	}
+
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+
+	return promotedField{}, false
 }
 
-func createEnvVar(field *structs.Field) *EnvVar {
-	newEnv := &EnvVar{}
-	newEnv.setKey(field)
+// fieldByIndex returns the field of the given struct with the given index sequence. Nil embedded pointers are
+// replaced with the zero value of the struct they point to.
+func fieldByIndex(s reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			s = indirect(s)
+		}
+
+		s = s.Field(x)
+	}
 
-	return newEnv
+	return s
+}
+
+func (v *Viewer) createEnvVar(field Field) *EnvVar {
+	return &EnvVar{
+		key:            v.naming.FieldEnv(field),
+		field:          field.Name,
+		segment:        v.naming.FieldPath(field),
+		ConfigField:    v.naming.FieldPath(field),
+		tagDescription: tagDescription(field.StructField),
+		Constraints:    parseConstraints(field.Tag.Get(validateTag)),
+	}
 }
 
 func ensureConfigFieldEndsWithDot(configField string) string {
@@ -251,8 +469,156 @@ func ensureConfigFieldEndsWithDot(configField string) string {
 	return configField
 }
 
-func handleStructField(newEnv *EnvVar, field *structs.Field, prefix, configField string, envs *[]*EnvVar) {
-	envsInner := parseEnvs(field.Value(), prefix+newEnv.key+"_", configField+newEnv.ConfigField)
+// indirect dereferences the given value until it is neither a pointer nor an interface anymore. Interfaces are
+// replaced with their concrete value. Nil pointers are replaced with the zero value of the type they point to, so
+// that the fields of optional structures are still reported. Nil interfaces are returned as is.
+func indirect(v reflect.Value) reflect.Value {
+	for {
+		switch {
+		case v.Kind() == reflect.Ptr && v.IsNil():
+			v = reflect.Zero(v.Type().Elem())
+		case v.Kind() == reflect.Ptr, v.Kind() == reflect.Interface && !v.IsNil():
+			v = v.Elem()
+		default:
+			return v
+		}
+	}
+}
+
+// isRecursive reports whether the given value is a nil pointer to one of the struct types being parsed, or a pointer to
+// one of the structs being parsed. Such pointers are not expanded, since they would be expanded endlessly.
+func isRecursive(value reflect.Value, visited *visitedValues) bool {
+	for value.Kind() == reflect.Ptr || (value.Kind() == reflect.Interface && !value.IsNil()) {
+		if value.Kind() == reflect.Ptr && value.IsNil() {
+			t := value.Type().Elem()
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+
+			return visited.types[t]
+		}
+
+		if value.Kind() == reflect.Ptr && visited.pointers[pointerKey{addr: value.Pointer(), t: value.Type().Elem()}] {
+			return true
+		}
+
+		value = value.Elem()
+	}
+
+	return false
+}
+
+// unsetRecursive returns the zero value of the given value if it is a recursive pointer, which is exposed as unset
+// since the struct it points to is already exposed, or the given value otherwise.
+func unsetRecursive(value reflect.Value, visited *visitedValues) reflect.Value {
+	if isRecursive(value, visited) {
+		return reflect.Zero(value.Type())
+	}
+
+	return value
+}
+
+// dynamicType returns the name of the concrete type of the given value if it is declared as an interface, and an
+// empty string otherwise.
+func dynamicType(declared reflect.Type, value reflect.Value) string {
+	for declared.Kind() == reflect.Ptr {
+		declared = declared.Elem()
+	}
+
+	if declared.Kind() != reflect.Interface || !value.IsValid() || value.Kind() == reflect.Interface {
+		return ""
+	}
+
+	return typeName(value.Type())
+}
+
+// stringValue returns the string representation of the given value, dereferencing pointers. Nil pointers are
+// represented as an empty string. Slices and maps are represented as comma separated lists, e.g. 'a,b,c' for slices
+// and 'k1:v1,k2:v2' for maps.
+func stringValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	switch {
+	case !v.IsValid():
+		return ""
+	case isFormatted(v):
+		return formatterFor(v.Type())(v)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		// Byte slices are set from the bytes of their environment variable, as envconfig does.
+		return string(v.Bytes())
+	case isSliceOrArray(v):
+		elems := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, stringValue(v.Index(i)))
+		}
+
+		return strings.Join(elems, ",")
+	case v.Kind() == reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			pairs = append(pairs, fmt.Sprintf("%v:%s", key, stringValue(v.MapIndex(key))))
+		}
+
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// typedValue returns the underlying value of the given value, dereferencing pointers and interfaces. Nil pointers
+// and interfaces are represented as nil.
+func typedValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return nil
+	}
+
+	if formatter := formatterFor(v.Type()); formatter != nil {
+		return formatter(v)
+	}
+
+	return v.Interface()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// typeName returns the name of the given type as exposed in EnvVar.Type, e.g. 'int', '[]string' or 'duration'.
+// Pointer types are represented by the type they point to.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		return "duration"
+	}
+
+	return t.String()
+}
+
+func (v *Viewer) handleStructField(newEnv *EnvVar,
+	value reflect.Value,
+	prefix, configField string,
+	envs *[]*EnvVar,
+	visited *visitedValues,
+) {
+	envPrefix := prefix + newEnv.key + v.naming.Separator()
+	envsInner := v.parseFields(value, envPrefix, configField+newEnv.ConfigField, newEnv.goPath, visited)
 	kvEnvVar := makeKVEnvVar(envsInner)
 
 	newEnv.Value = kvEnvVar
@@ -262,52 +628,180 @@ func handleStructField(newEnv *EnvVar, field *structs.Field, prefix, configField
 	*envs = append(*envs, newEnv)
 }
 
-func handleMapField(newEnv *EnvVar, field *structs.Field, prefix, configField string, envs *[]*EnvVar) {
-	v := reflect.ValueOf(field.Value())
-	keys := v.MapKeys()
+func (v *Viewer) handleMapField(newEnv *EnvVar,
+	m reflect.Value,
+	prefix, configField string,
+	envs *[]*EnvVar,
+	visited *visitedValues,
+) {
+	keys := m.MapKeys()
 	kvEnvVar := make(map[string]*EnvVar)
 
 	for _, key := range keys {
-		value := v.MapIndex(key)
+		value := m.MapIndex(key)
 		keyStr := fmt.Sprintf("%v", key)
-		mapEnv := &EnvVar{key: keyStr, field: keyStr}
+		mapEnv := &EnvVar{
+			key:     v.naming.MapKeyEnv(keyStr),
+			field:   keyStr,
+			goPath:  joinGoPath(newEnv.goPath, keyStr),
+			segment: v.naming.MapKeyPath(keyStr),
+		}
+		elem := indirect(value)
+		mapEnv.Type = dynamicType(m.Type().Elem(), elem)
+		_, isMasked := v.masks[mapEnv.goPath]
 
-		if value.Kind() == reflect.Struct {
-			processStructInMapForEnvs(value.Interface(), prefix, newEnv, configField, kvEnvVar)
-		} else {
-			processSimpleValueInMap(mapEnv, value.Interface(), prefix, newEnv, configField, kvEnvVar)
+		switch {
+		case isMasked, isFormatted(elem), isRecursive(value, visited):
+			v.processSimpleValueInMap(mapEnv, unsetRecursive(value, visited), prefix, newEnv, configField, kvEnvVar)
+		case elem.Kind() == reflect.Struct:
+			v.processStructInMapForEnvs(mapEnv, elem, prefix, newEnv, configField, kvEnvVar, visited)
+		case isSliceOrArray(elem) && elem.Len() > 0:
+			v.processSliceInMapForEnvs(mapEnv, elem, prefix, newEnv, configField, kvEnvVar, visited)
+		default:
+			v.processSimpleValueInMap(mapEnv, value, prefix, newEnv, configField, kvEnvVar)
 		}
 	}
 
 	newEnv.Value = kvEnvVar
 	newEnv.ConfigField = ""
 	newEnv.isStruct = true
+	newEnv.collectionEnv = prefix + newEnv.key
+
+	*envs = append(*envs, newEnv)
+}
+
+func (v *Viewer) handleSliceField(newEnv *EnvVar,
+	s reflect.Value,
+	prefix, configField string,
+	envs *[]*EnvVar,
+	visited *visitedValues,
+) {
+	envPrefix := prefix + newEnv.key + v.naming.Separator()
+	newEnv.Value = v.parseSliceElements(s, envPrefix, configField+newEnv.ConfigField+".", newEnv.goPath, visited)
+	newEnv.ConfigField = ""
+	newEnv.isStruct = true
+	newEnv.collectionEnv = prefix + newEnv.key
 
 	*envs = append(*envs, newEnv)
 }
 
-func processStructInMapForEnvs(value interface{},
+// parseSliceElements generates an EnvVar for each element of the given slice or array, using the element index as
+// its key. For example, the address of the first element of 'hosts' is represented as 'hosts.0.addr' in JSON
+// notation and as 'HOSTS_0_ADDR' in environment variable notation.
+func (v *Viewer) parseSliceElements(s reflect.Value,
+	prefix, configField, goPath string,
+	visited *visitedValues,
+) map[string]*EnvVar {
+	kvEnvVar := make(map[string]*EnvVar)
+
+	for i := 0; i < s.Len(); i++ {
+		elem := indirect(s.Index(i))
+		idx := strconv.Itoa(i)
+		envIdx := v.naming.IndexEnv(i)
+		pathIdx := v.naming.IndexPath(i)
+		elemEnv := &EnvVar{key: envIdx, field: idx, goPath: joinGoPath(goPath, idx), segment: pathIdx}
+		elemEnv.Type = dynamicType(s.Type().Elem(), elem)
+
+		switch masked, isMasked := v.masks[elemEnv.goPath]; {
+		case isMasked:
+			elemEnv.setMasked(masked, s.Type().Elem())
+			elemEnv.Env = prefix + envIdx
+			elemEnv.ConfigField = configField + pathIdx
+		case isFormatted(elem), isRecursive(s.Index(i), visited):
+			elemEnv.setValue(unsetRecursive(s.Index(i), visited))
+			elemEnv.Env = prefix + envIdx
+			elemEnv.ConfigField = configField + pathIdx
+			elemEnv.Obfuscated = getPointerBool(false)
+		case elem.Kind() == reflect.Struct:
+			envPrefix := prefix + envIdx + v.naming.Separator()
+			envsInner := v.parseFields(elem, envPrefix, configField+pathIdx, elemEnv.goPath, visited)
+			elemEnv.Value = makeKVEnvVar(envsInner)
+			elemEnv.isStruct = true
+		case elem.Kind() == reflect.Map:
+			// The entries of the map are exposed as the entries of a map field whose key is the element index.
+			elemEnv.ConfigField = pathIdx
+			v.handleMapField(elemEnv, elem, prefix, configField, &[]*EnvVar{}, visited)
+		case isSliceOrArray(elem) && elem.Len() > 0:
+			envPrefix := prefix + envIdx + v.naming.Separator()
+			elemEnv.Value = v.parseSliceElements(elem, envPrefix, configField+pathIdx+".", elemEnv.goPath, visited)
+			elemEnv.isStruct = true
+		default:
+			elemEnv.setValue(s.Index(i))
+			elemEnv.Env = prefix + envIdx
+			elemEnv.ConfigField = configField + pathIdx
+			elemEnv.Obfuscated = getPointerBool(false)
+
+			if v.hasMasks(elemEnv.goPath) {
+				// Collections exposed as a single environment variable include their masked values.
+				elemEnv.Obfuscated = getPointerBool(true)
+			}
+		}
+
+		kvEnvVar[idx] = elemEnv
+	}
+
+	return kvEnvVar
+}
+
+// isSliceOrArray reports whether the given value is a slice or an array that needs to be expanded element by
+// element. Byte slices are considered as simple values.
+func isSliceOrArray(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+
+	return v.Type().Elem().Kind() != reflect.Uint8
+}
+
+// processStructInMapForEnvs generates the EnvVar of a struct stored in a map. The map key is part of the notations
+// of the struct fields, e.g. the 'id' field of the 'key_99' entry of 'metadata' is represented as
+// 'metadata.key_99.id' in JSON notation and as 'METADATA_KEY99_ID' in environment variable notation.
+func (v *Viewer) processStructInMapForEnvs(mapEnv *EnvVar,
+	value reflect.Value,
 	prefix string,
 	newEnv *EnvVar,
 	configField string,
 	kvEnvVar map[string]*EnvVar,
+	visited *visitedValues,
 ) {
-	envsInner := parseEnvs(value, prefix+newEnv.key+"_", configField+newEnv.ConfigField)
-	for i := range envsInner {
-		kvEnvVar[envsInner[i].field] = envsInner[i]
-	}
+	envPrefix := prefix + newEnv.key + v.naming.Separator() + mapEnv.key + v.naming.Separator()
+	pathPrefix := configField + newEnv.ConfigField + "." + v.naming.MapKeyPath(mapEnv.field)
+	envsInner := v.parseFields(value, envPrefix, pathPrefix, mapEnv.goPath, visited)
+
+	mapEnv.Value = makeKVEnvVar(envsInner)
+	mapEnv.isStruct = true
+
+	kvEnvVar[mapEnv.field] = mapEnv
+}
+
+func (v *Viewer) processSliceInMapForEnvs(mapEnv *EnvVar,
+	value reflect.Value,
+	prefix string,
+	newEnv *EnvVar,
+	configField string,
+	kvEnvVar map[string]*EnvVar,
+	visited *visitedValues,
+) {
+	envPrefix := prefix + newEnv.key + v.naming.Separator() + mapEnv.key + v.naming.Separator()
+	pathPrefix := configField + newEnv.ConfigField + "." + v.naming.MapKeyPath(mapEnv.field) + "."
+
+	mapEnv.Value = v.parseSliceElements(value, envPrefix, pathPrefix, mapEnv.goPath, visited)
+	mapEnv.isStruct = true
+
+	kvEnvVar[mapEnv.field] = mapEnv
 }
 
-func handleSimpleField(newEnv *EnvVar, field *structs.Field, prefix, configField string, envs *[]*EnvVar) {
-	newEnv.setValue(field)
+func handleSimpleField(newEnv *EnvVar,
+	value reflect.Value,
+	prefix string,
+	configField string,
+	envs *[]*EnvVar,
+) {
+	newEnv.setValue(value)
 	newEnv.Env = prefix + newEnv.key
 	newEnv.ConfigField = configField + newEnv.ConfigField
 	newEnv.Obfuscated = getPointerBool(false)
 
-	if field.Tag(StructViewerTag) == "obfuscate" {
-		newEnv.Obfuscated = getPointerBool(true)
-	}
-
 	*envs = append(*envs, newEnv)
 }
 
@@ -320,35 +814,32 @@ func makeKVEnvVar(envsInner []*EnvVar) map[string]*EnvVar {
 	return kvEnvVar
 }
 
-func processStructInMap(mapValue reflect.Value) (reflect.Value, error) {
-	ptrToStruct := reflect.New(mapValue.Type())
-	ptrToStruct.Elem().Set(mapValue)
-
-	newStruct, err := obfuscateTags(ptrToStruct.Interface())
-	if err != nil {
-		return reflect.Value{}, err
-	}
-
-	return reflect.ValueOf(newStruct).Elem(), nil
-}
-
-func processSimpleValueInMap(mapEnv *EnvVar,
-	value interface{},
+func (v *Viewer) processSimpleValueInMap(mapEnv *EnvVar,
+	value reflect.Value,
 	prefix string,
 	newEnv *EnvVar,
 	configField string,
 	kvEnvVar map[string]*EnvVar,
 ) {
-	mapEnv.Value = value
-	envSuffix := strings.ToUpper(strings.ReplaceAll(mapEnv.key, "_", ""))
-	mapEnv.Env = prefix + newEnv.key + "_" + envSuffix
-	mapEnv.ConfigField = configField + newEnv.ConfigField + "." + mapEnv.key
+	mapEnv.setValue(value)
+	mapEnv.Env = prefix + newEnv.key + v.naming.Separator() + mapEnv.key
+	mapEnv.ConfigField = configField + newEnv.ConfigField + "." + v.naming.MapKeyPath(mapEnv.field)
 	mapEnv.Obfuscated = getPointerBool(false)
 
-	kvEnvVar[mapEnv.key] = mapEnv
+	if masked, ok := v.masks[mapEnv.goPath]; ok {
+		mapEnv.setMasked(masked, value.Type())
+	} else if v.hasMasks(mapEnv.goPath) {
+		// Collections exposed as a single environment variable include their masked values.
+		mapEnv.Obfuscated = getPointerBool(true)
+	}
+
+	kvEnvVar[mapEnv.field] = mapEnv
 }
 
-func obfuscateTags(config interface{}) (interface{}, error) {
+// obfuscateTags masks the fields of the given struct which are redacted, either because they are tagged with the
+// obfuscate option or because they match the redaction policy. path and env are the JSON and environment variable
+// notations of the struct, used as prefixes of the notations of its fields, and goPath is its Go path.
+func (v *Viewer) obfuscateTags(config interface{}, path, env, goPath string) (interface{}, error) {
 	val := reflect.ValueOf(config)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
@@ -359,18 +850,36 @@ func obfuscateTags(config interface{}) (interface{}, error) {
 	}
 
 	typ := val.Type()
+	path = ensureConfigFieldEndsWithDot(path)
 
 	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
+		field := newField(typ.Field(i), v.tagKeys)
 		fieldValue := val.Field(i)
 
 		if !fieldValue.CanSet() {
 			continue
 		}
 
-		svTag := field.Tag.Get(StructViewerTag)
+		fieldPath := path + v.naming.FieldPath(field)
+		fieldEnv := env + v.naming.FieldEnv(field)
+		fieldGoPath := joinGoPath(goPath, field.Name)
+
+		masked, err := v.maskField(field, fieldValue, fieldPath, fieldEnv, fieldGoPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if masked {
+			continue
+		}
+
+		// The notations of the fields nested in the value of the field, matching the ones generated by parseEnvs.
+		innerPath, innerEnv := fieldPath, fieldEnv+v.naming.Separator()
+		if elem := indirect(fieldValue); elem.Kind() == reflect.Struct && !isFormatted(elem) && v.naming.Flatten(field) {
+			innerPath, innerEnv = path, env
+		}
 
-		if err := processField(fieldValue, svTag); err != nil {
+		if err := v.processField(fieldValue, innerPath, innerEnv, fieldGoPath); err != nil {
 			return nil, err
 		}
 	}
@@ -378,28 +887,33 @@ func obfuscateTags(config interface{}) (interface{}, error) {
 	return config, nil
 }
 
-func processField(fieldValue reflect.Value, svTag string) error {
+// processField masks the redacted values nested in the given value. path and env are the prefixes of the JSON and
+// environment variable notations of the values nested in the given value, and goPath is its Go path.
+func (v *Viewer) processField(fieldValue reflect.Value, path, env, goPath string) error {
 	switch fieldValue.Kind() {
 	case reflect.Struct:
-		return processStructField(fieldValue, svTag)
+		return v.processStructField(fieldValue, path, env, goPath)
 	case reflect.Map:
-		return processMapField(fieldValue, svTag)
+		return v.processMapField(fieldValue, path, env, goPath)
+	case reflect.Slice:
+		return v.processSliceField(fieldValue, path, env, goPath)
+	case reflect.Array:
+		return v.processElements(fieldValue, path, env, goPath)
+	case reflect.Ptr:
+		return v.processPointerField(fieldValue, path, env, goPath)
+	case reflect.Interface:
+		return v.processInterfaceField(fieldValue, path, env, goPath)
 	default:
-		processSimpleField(fieldValue, svTag)
+		return nil
 	}
-
-	return nil
 }
 
-func processStructField(fieldValue reflect.Value, svTag string) error {
-	if strings.EqualFold(svTag, "obfuscate") {
-		zeroValue := reflect.Zero(fieldValue.Type())
-		fieldValue.Set(zeroValue)
-
+func (v *Viewer) processStructField(fieldValue reflect.Value, path, env, goPath string) error {
+	if isFormatted(fieldValue) {
 		return nil
 	}
 
-	newStruct, err := obfuscateTags(fieldValue.Addr().Interface())
+	newStruct, err := v.obfuscateTags(fieldValue.Addr().Interface(), path, env, goPath)
 	if err != nil {
 		return err
 	}
@@ -409,11 +923,8 @@ func processStructField(fieldValue reflect.Value, svTag string) error {
 	return nil
 }
 
-func processMapField(fieldValue reflect.Value, svTag string) error {
-	if strings.EqualFold(svTag, "obfuscate") {
-		zeroValue := reflect.Zero(fieldValue.Type())
-		fieldValue.Set(zeroValue)
-
+func (v *Viewer) processMapField(fieldValue reflect.Value, path, env, goPath string) error {
+	if fieldValue.IsNil() {
 		return nil
 	}
 
@@ -426,7 +937,11 @@ func processMapField(fieldValue reflect.Value, svTag string) error {
 			continue
 		}
 
-		newValue, err := processMapValue(mapValue)
+		keyStr := fmt.Sprintf("%v", key)
+		keyPath := ensureConfigFieldEndsWithDot(path) + v.naming.MapKeyPath(keyStr)
+		keyEnv := env + v.naming.MapKeyEnv(keyStr) + v.naming.Separator()
+
+		newValue, err := v.processMapValue(mapValue, keyPath, keyEnv, joinGoPath(goPath, keyStr))
 		if err != nil {
 			return err
 		}
@@ -439,44 +954,117 @@ func processMapField(fieldValue reflect.Value, svTag string) error {
 	return nil
 }
 
-func processMapValue(mapValue reflect.Value) (reflect.Value, error) {
-	switch mapValue.Kind() {
-	case reflect.Ptr, reflect.Interface:
-		return processPointerOrInterface(mapValue)
-	case reflect.Struct:
-		return processStructInMap(mapValue)
-	default:
-		return mapValue, nil
+func (v *Viewer) processPointerField(fieldValue reflect.Value, path, env, goPath string) error {
+	// Nil pointers have a zero address, so that the zero values of their recursive types are walked only once.
+	key := pointerKey{addr: fieldValue.Pointer(), t: fieldValue.Type().Elem()}
+	if v.copying[key] {
+		// The pointer points back to a value being copied, so it is unset as in the environment variables: the copy
+		// has no cycle and can be encoded.
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		return nil
+	}
+
+	if fieldValue.IsNil() {
+		// The fields of the structs pointed by nil pointers are exposed with their zero value, so the zero value is
+		// walked to mask them as well. The pointer is left nil.
+		v.copying[key] = true
+		defer delete(v.copying, key)
+
+		return v.processField(reflect.New(fieldValue.Type().Elem()).Elem(), path, env, goPath)
+	}
+
+	// The pointed value is shared with the original configuration, so it is copied into a new value before being
+	// obfuscated.
+	newValue := reflect.New(fieldValue.Type().Elem())
+	newValue.Elem().Set(fieldValue.Elem())
+
+	v.copying[key] = true
+	defer delete(v.copying, key)
+
+	if err := v.processField(newValue.Elem(), path, env, goPath); err != nil {
+		return err
 	}
+
+	fieldValue.Set(newValue)
+
+	return nil
 }
 
-func processPointerOrInterface(mapValue reflect.Value) (reflect.Value, error) {
-	elemValue := mapValue.Elem()
-	if elemValue.Kind() == reflect.Struct {
-		newStruct, err := obfuscateTags(elemValue.Addr().Interface())
-		if err != nil {
-			return reflect.Value{}, err
-		}
+// processInterfaceField obfuscates the concrete value of the given interface. The concrete value is not addressable,
+// so it is copied into a new value before being obfuscated.
+func (v *Viewer) processInterfaceField(fieldValue reflect.Value, path, env, goPath string) error {
+	if fieldValue.IsNil() {
+		return nil
+	}
+
+	newValue := reflect.New(fieldValue.Elem().Type()).Elem()
+	newValue.Set(fieldValue.Elem())
 
-		return reflect.ValueOf(newStruct).Elem(), nil
+	if err := v.processField(newValue, path, env, goPath); err != nil {
+		return err
 	}
 
-	return mapValue, nil
+	fieldValue.Set(newValue)
+
+	return nil
 }
 
-func processSimpleField(fieldValue reflect.Value, svTag string) {
-	if strings.EqualFold(svTag, "obfuscate") {
-		if fieldValue.Kind() == reflect.String {
-			if fieldValue.String() != "" {
-				fieldValue.SetString("*REDACTED*")
-			}
-		} else {
-			zeroValue := reflect.Zero(fieldValue.Type())
-			if !reflect.DeepEqual(fieldValue.Interface(), zeroValue.Interface()) {
-				fieldValue.Set(zeroValue)
-			}
+func (v *Viewer) processSliceField(fieldValue reflect.Value, path, env, goPath string) error {
+	if fieldValue.IsNil() {
+		return nil
+	}
+
+	// The slice shares its backing array with the original configuration, so the elements are copied into a new
+	// slice before being obfuscated.
+	newSlice := reflect.MakeSlice(fieldValue.Type(), fieldValue.Len(), fieldValue.Len())
+	reflect.Copy(newSlice, fieldValue)
+
+	if err := v.processElements(newSlice, path, env, goPath); err != nil {
+		return err
+	}
+
+	fieldValue.Set(newSlice)
+
+	return nil
+}
+
+// processElements masks the redacted values nested in the elements of the given slice or array.
+func (v *Viewer) processElements(s reflect.Value, path, env, goPath string) error {
+	for i := 0; i < s.Len(); i++ {
+		elemPath := ensureConfigFieldEndsWithDot(path) + v.naming.IndexPath(i)
+		elemEnv := env + v.naming.IndexEnv(i) + v.naming.Separator()
+		elemGoPath := joinGoPath(goPath, strconv.Itoa(i))
+
+		if hasCredentials(s.Index(i)) {
+			v.mask(s.Index(i), elemGoPath, URLRedactor, typeRule)
+			continue
 		}
+
+		if err := v.processField(s.Index(i), elemPath, elemEnv, elemGoPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processMapValue returns a copy of the given map value whose redacted values are masked. Map values are not
+// addressable, so they are copied into a new value before being obfuscated, whatever their kind.
+func (v *Viewer) processMapValue(mapValue reflect.Value, path, env, goPath string) (reflect.Value, error) {
+	newValue := reflect.New(mapValue.Type()).Elem()
+	newValue.Set(mapValue)
+
+	if hasCredentials(newValue) {
+		v.mask(newValue, goPath, URLRedactor, typeRule)
+
+		return newValue, nil
 	}
+
+	if err := v.processField(newValue, path, env, goPath); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return newValue, nil
 }
 
 // EnvVar is a key:value string struct for environment variables representation
@@ -492,6 +1080,24 @@ type EnvVar struct {
 	field string `json:"-"`
 	// isStruct is used internally to determine whether the given struct field is a struct or not.
 	isStruct bool `json:"-"`
+	// envValue represents the value of the given struct fields in environment variable notation.
+	envValue string `json:"-"`
+	// goPath is the Go path of the given struct fields, e.g. 'Upstreams.0.Token'. It identifies the fields whatever
+	// the naming strategy.
+	goPath string `json:"-"`
+	// tagDescription, doc and lineComment are the descriptions of the given struct fields given by their tags, their
+	// doc comments and their trailing line comments. Description is the first one set, in the order of
+	// Config.DescriptionSources.
+	tagDescription string `json:"-"`
+	doc            string `json:"-"`
+	lineComment    string `json:"-"`
+	// modified represents whether the value of the given struct fields differs from their default value.
+	modified bool `json:"-"`
+	// collectionEnv is the environment variable setting the given collection as a whole, e.g. 'TAGS' for 'a,b,c'.
+	collectionEnv string `json:"-"`
+	// segment is the last segment of the JSON notation of the given struct fields, e.g. 'url' for 'upstreams.0.url'.
+	// The JSON notation cannot be split on dots, since map keys may hold dots.
+	segment string `json:"-"`
 
 	// ConfigField represents a JSON notation of the given struct fields.
 	ConfigField string `json:"config_field,omitempty"`
@@ -499,40 +1105,67 @@ type EnvVar struct {
 	Env string `json:"env,omitempty"`
 	// Description represents the comment of the given struct fields.
 	Description string `json:"description,omitempty"`
-	// Value represents the value of the given struct fields.
+	// Value represents the value of the given struct fields, keeping its original type.
 	Value interface{} `json:"value"`
+	// Type represents the type of the given struct fields, e.g. 'int', 'bool', 'duration', 'string' or '[]string'.
+	// Types other than the built-in ones are represented by their Go type name.
+	Type string `json:"type,omitempty"`
 	// Obfuscated represents whether the given struct field is obfuscated or not.
 	// This is a pointer to a boolean value to distinguish between the zero value
 	// and the actual value (because of the 'omitempty' tag).
 	Obfuscated *bool `json:"obfuscated,omitempty"`
+	// Default represents the default value of the given struct fields, from their `default:"..."` tag or from
+	// Config.Defaults. It is not exposed for obfuscated fields.
+	Default interface{} `json:"default,omitempty"`
+	// IsDefault represents whether the given struct fields are set to their default value. It is only set if their
+	// default value is known.
+	IsDefault *bool `json:"is_default,omitempty"`
+	// Source represents where the value of the given struct fields comes from. It is only set if Config.Environ or
+	// Config.File is given.
+	Source ValueSource `json:"source,omitempty"`
+	// SourceEnv represents the environment variable setting the value of the given struct fields, if their Source is
+	// EnvSource. It is the variable of a whole collection if the collection is set by a single variable.
+	SourceEnv string `json:"source_env,omitempty"`
+	// Constraints represents the validation constraints of the given struct fields, from the supported subset of their
+	// `validate:"..."` tag.
+	Constraints []Constraint `json:"constraints,omitempty"`
 }
 
 // String returns a key:value string from EnvVar
 func (ev EnvVar) String() string {
-	return fmt.Sprintf("%s:%s", ev.Env, ev.Value)
+	return fmt.Sprintf("%s:%s", ev.Env, ev.envValue)
 }
 
-func (ev *EnvVar) setKey(field *structs.Field) {
-	key := field.Name()
-	jsonTag := field.Tag("json")
+// EnvValue returns the value of the given struct field as it is set in its environment variable, e.g. '8080' or
+// 'a,b,c'.
+func (ev EnvVar) EnvValue() string {
+	return ev.envValue
+}
+
+func (ev *EnvVar) setValue(value reflect.Value) {
+	// Interfaces are represented by their concrete value, dereferencing the pointers to interfaces.
+	for value.Kind() == reflect.Interface || (value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Interface) {
+		if value.IsNil() {
+			break
+		}
 
-	if jsonTag != "" && jsonTag != "-" {
-		jsonTag = strings.ReplaceAll(jsonTag, ",omitempty", "")
-		key = jsonTag
+		value = value.Elem()
 	}
 
-	key = strings.ReplaceAll(key, "_", "")
-	key = strings.ToUpper(key)
-	ev.key = key
-	ev.ConfigField = jsonTag
-	ev.field = field.Name()
+	ev.Value = typedValue(value)
+	ev.Type = typeName(value.Type())
+	ev.envValue = stringValue(value)
 }
 
-func (ev *EnvVar) setValue(field *structs.Field) {
-	if structs.IsStruct(field.Value()) {
-		ev.Value = fmt.Sprintf("%+v", field.Value())
-		return
+// setMasked sets the masked representation of an obfuscated value of the given type. The concrete type of the values
+// declared as interfaces, if already set, is kept.
+func (ev *EnvVar) setMasked(masked string, t reflect.Type) {
+	ev.Value = masked
+
+	if ev.Type == "" {
+		ev.Type = typeName(t)
 	}
 
-	ev.Value = fmt.Sprint(field.Value())
+	ev.envValue = masked
+	ev.Obfuscated = getPointerBool(true)
 }
@@ -2,9 +2,12 @@ package structviewer
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -144,6 +147,83 @@ func TestParseEnvsValues(t *testing.T) {
 			expectedLen:  4,
 			expectedEnvs: []string{"KEY=Value", "MAP_KEY1=value1", "MAP_KEY2=value2", "STR_INNER=inner"},
 		},
+		{
+			testName: "slice of simple values",
+			testStruct: struct {
+				Hosts []string `json:"hosts"`
+			}{
+				Hosts: []string{"a", "b", "c"},
+			},
+			expectedLen:  3,
+			expectedEnvs: []string{"HOSTS_0=a", "HOSTS_1=b", "HOSTS_2=c"},
+		},
+		{
+			testName: "slice of structs",
+			testStruct: struct {
+				Upstreams []struct {
+					Addr   string `json:"addr"`
+					Weight int    `json:"weight"`
+				} `json:"upstreams"`
+			}{
+				Upstreams: []struct {
+					Addr   string `json:"addr"`
+					Weight int    `json:"weight"`
+				}{
+					{Addr: "a:80", Weight: 1},
+					{Addr: "b:80", Weight: 2},
+				},
+			},
+			expectedLen: 4,
+			expectedEnvs: []string{
+				"UPSTREAMS_0_ADDR=a:80", "UPSTREAMS_0_WEIGHT=1",
+				"UPSTREAMS_1_ADDR=b:80", "UPSTREAMS_1_WEIGHT=2",
+			},
+		},
+		{
+			testName: "array and nested slices",
+			testStruct: struct {
+				Ports  [2]int     `json:"ports"`
+				Matrix [][]string `json:"matrix"`
+			}{
+				Ports:  [2]int{80, 443},
+				Matrix: [][]string{{"a"}, {"b", "c"}},
+			},
+			expectedLen:  5,
+			expectedEnvs: []string{"PORTS_0=80", "PORTS_1=443", "MATRIX_0_0=a", "MATRIX_1_0=b", "MATRIX_1_1=c"},
+		},
+		{
+			testName: "empty and nil slices",
+			testStruct: struct {
+				Hosts  []string   `json:"hosts"`
+				Ports  []int      `json:"ports"`
+				Matrix [][]string `json:"matrix"`
+			}{
+				Hosts:  []string{},
+				Matrix: [][]string{{}},
+			},
+			expectedLen:  3,
+			expectedEnvs: []string{"HOSTS=''", "PORTS=''", "MATRIX_0=''"},
+		},
+		{
+			testName: "byte slice as simple value",
+			testStruct: struct {
+				Raw []byte `json:"raw"`
+			}{
+				Raw: []byte("ab"),
+			},
+			expectedLen:  1,
+			expectedEnvs: []string{"RAW=ab"},
+		},
+		{
+			testName: "maps in slices",
+			testStruct: struct {
+				Routes []map[string]string `json:"routes"`
+			}{
+				Routes: []map[string]string{{"path": "/api", "host": "eu"}, {}},
+			},
+			expectedLen:  2,
+			expectedEnvs: []string{"ROUTES_0_PATH=/api", "ROUTES_0_HOST=eu"},
+		},
 	}
 
 	for _, tc := range tcs {
@@ -211,10 +291,31 @@ func TestParseEnvsPrefix(t *testing.T) {
 	}
 }
 
+func TestSliceNotation(t *testing.T) {
+	type upstream struct {
+		Addr string `json:"addr"`
+	}
+
+	viewer, err := New(&Config{Object: struct {
+		Hosts []upstream `json:"hosts"`
+	}{
+		Hosts: []upstream{{Addr: "a:80"}, {Addr: "b:80"}},
+	}}, "PREFIX_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	envVar := viewer.EnvNotation("hosts.1.addr")
+	assert.Equal(t, "PREFIX_HOSTS_1_ADDR", envVar.Env)
+	assert.Equal(t, "b:80", envVar.Value)
+
+	envVar = viewer.JSONNotation("PREFIX_HOSTS_0_ADDR")
+	assert.Equal(t, "hosts.0.addr", envVar.ConfigField)
+	assert.Equal(t, "a:80", envVar.Value)
+}
+
 func TestParseComments(t *testing.T) {
 	viewer, err := New(&Config{Object: testStruct{}, Path: "./parser_test.go"}, "TYK_")
 	assert.NoError(t, err, "failed to instantiate viewer")
-	err = viewer.parseComments()
+	err = viewer.describeViews(true)
 	assert.NoError(t, err, "failed to parse comments")
 
 	for _, env := range viewer.Envs() {
@@ -438,6 +539,52 @@ func TestObfuscateTags(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "obfuscate fields of slice elements",
+			given: &struct {
+				Upstreams []struct {
+					Addr  string `json:"addr"`
+					Token string `json:"token" structviewer:"obfuscate"`
+				} `json:"upstreams"`
+				Certs [1]struct {
+					Key string `json:"key" structviewer:"obfuscate"`
+				} `json:"certs"`
+			}{
+				Upstreams: []struct {
+					Addr  string `json:"addr"`
+					Token string `json:"token" structviewer:"obfuscate"`
+				}{
+					{Addr: "a:80", Token: "secret"},
+				},
+				Certs: [1]struct {
+					Key string `json:"key" structviewer:"obfuscate"`
+				}{
+					{Key: "private"},
+				},
+			},
+			want: &struct {
+				Upstreams []struct {
+					Addr  string `json:"addr"`
+					Token string `json:"token" structviewer:"obfuscate"`
+				} `json:"upstreams"`
+				Certs [1]struct {
+					Key string `json:"key" structviewer:"obfuscate"`
+				} `json:"certs"`
+			}{
+				Upstreams: []struct {
+					Addr  string `json:"addr"`
+					Token string `json:"token" structviewer:"obfuscate"`
+				}{
+					{Addr: "a:80", Token: "*REDACTED*"},
+				},
+				Certs: [1]struct {
+					Key string `json:"key" structviewer:"obfuscate"`
+				}{
+					{Key: "*REDACTED*"},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "Handle error when config is not pointer",
 			given:   struct{}{},
@@ -448,7 +595,16 @@ func TestObfuscateTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := obfuscateTags(tt.given)
+			v := Viewer{
+				naming:    DefaultNaming,
+				tagKeys:   []string{"json"},
+				redactors: newRedactors(nil, ""),
+				masks:     map[string]string{},
+				rules:     map[string]redactionRule{},
+				copying:   map[pointerKey]bool{},
+			}
+
+			got, err := v.obfuscateTags(tt.given, "", "", "")
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("obfuscateTags() error = %v, wantErr %v", err, tt.wantErr)
@@ -461,3 +617,684 @@ func TestObfuscateTags(t *testing.T) {
 		})
 	}
 }
+
+func TestObfuscateSliceDoesNotModifyOriginal(t *testing.T) {
+	type upstream struct {
+		Token string `json:"token" structviewer:"obfuscate"`
+	}
+
+	config := struct {
+		Upstreams []upstream `json:"upstreams"`
+	}{
+		Upstreams: []upstream{{Token: "secret"}},
+	}
+
+	viewer, err := New(&Config{Object: config}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "secret", config.Upstreams[0].Token)
+	assert.Equal(t, "*REDACTED*", viewer.EnvNotation("upstreams.0.token").Value)
+}
+
+type pointerTLSConfig struct {
+	// CertFile is the path of the TLS certificate.
+	CertFile string `json:"cert_file"`
+	// KeyPassword is the password of the TLS private key.
+	KeyPassword string `json:"key_password" structviewer:"obfuscate"`
+}
+
+type pointerUpstream struct {
+	Addr string            `json:"addr"`
+	TLS  *pointerTLSConfig `json:"tls"`
+}
+
+type pointerConfig struct {
+	// TLS is an optional TLS configuration.
+	TLS *pointerTLSConfig `json:"tls"`
+	// Upstream is an optional upstream configuration.
+	Upstream *pointerUpstream `json:"upstream"`
+	// Timeout is an optional timeout.
+	Timeout *int `json:"timeout"`
+	// Secret is an optional secret.
+	Secret *string `json:"secret" structviewer:"obfuscate"`
+	// Nested is a pointer to a pointer.
+	Nested **pointerTLSConfig `json:"nested"`
+}
+
+func TestPointerFields(t *testing.T) {
+	timeout := 30
+	secret := "secret"
+	nested := &pointerTLSConfig{CertFile: "nested.pem", KeyPassword: "nested_password"}
+
+	tcs := []struct {
+		testName     string
+		given        pointerConfig
+		expectedEnvs []string
+	}{
+		{
+			testName: "nil pointers",
+			given:    pointerConfig{},
+			expectedEnvs: []string{
+				"TLS_CERTFILE=''", "TLS_KEYPASSWORD=''",
+				"UPSTREAM_ADDR=''", "UPSTREAM_TLS_CERTFILE=''", "UPSTREAM_TLS_KEYPASSWORD=''",
+				"TIMEOUT=''", "SECRET=''", "NESTED_CERTFILE=''", "NESTED_KEYPASSWORD=''",
+			},
+		},
+		{
+			testName: "pointer to struct",
+			given: pointerConfig{
+				TLS: &pointerTLSConfig{CertFile: "cert.pem", KeyPassword: "password"},
+			},
+			expectedEnvs: []string{
+				"TLS_CERTFILE=cert.pem", "TLS_KEYPASSWORD=*REDACTED*",
+				"UPSTREAM_ADDR=''", "UPSTREAM_TLS_CERTFILE=''", "UPSTREAM_TLS_KEYPASSWORD=''",
+				"TIMEOUT=''", "SECRET=''", "NESTED_CERTFILE=''", "NESTED_KEYPASSWORD=''",
+			},
+		},
+		{
+			testName: "pointer to struct with pointer to struct",
+			given: pointerConfig{
+				Upstream: &pointerUpstream{
+					Addr: "localhost:80",
+					TLS:  &pointerTLSConfig{CertFile: "upstream.pem", KeyPassword: "upstream_password"},
+				},
+			},
+			expectedEnvs: []string{
+				"TLS_CERTFILE=''", "TLS_KEYPASSWORD=''",
+				"UPSTREAM_ADDR=localhost:80", "UPSTREAM_TLS_CERTFILE=upstream.pem",
+				"UPSTREAM_TLS_KEYPASSWORD=*REDACTED*",
+				"TIMEOUT=''", "SECRET=''", "NESTED_CERTFILE=''", "NESTED_KEYPASSWORD=''",
+			},
+		},
+		{
+			testName: "pointers to simple values and pointer to pointer",
+			given: pointerConfig{
+				Timeout: &timeout,
+				Secret:  &secret,
+				Nested:  &nested,
+			},
+			expectedEnvs: []string{
+				"TLS_CERTFILE=''", "TLS_KEYPASSWORD=''",
+				"UPSTREAM_ADDR=''", "UPSTREAM_TLS_CERTFILE=''", "UPSTREAM_TLS_KEYPASSWORD=''",
+				"TIMEOUT=30", "SECRET=*REDACTED*", "NESTED_CERTFILE=nested.pem", "NESTED_KEYPASSWORD=*REDACTED*",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: tc.given}, "")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.ElementsMatch(t, tc.expectedEnvs, viewer.ParseEnvs())
+		})
+	}
+}
+
+func TestObfuscateNilPointerFields(t *testing.T) {
+	viewer, err := New(&Config{Object: pointerConfig{}}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	for _, path := range []string{"tls.key_password", "upstream.tls.key_password", "nested.key_password", "secret"} {
+		envVar := viewer.EnvNotation(path)
+		assert.Equal(t, getPointerBool(true), envVar.Obfuscated, path)
+	}
+
+	assert.Equal(t, getPointerBool(false), viewer.EnvNotation("tls.cert_file").Obfuscated)
+	assert.Len(t, viewer.RedactionReport().Fields, 4)
+}
+
+func TestObfuscatePointerDoesNotModifyOriginal(t *testing.T) {
+	secret := "secret"
+	nested := &pointerTLSConfig{KeyPassword: "nested_password"}
+	config := &pointerConfig{
+		TLS:      &pointerTLSConfig{KeyPassword: "password"},
+		Upstream: &pointerUpstream{TLS: &pointerTLSConfig{KeyPassword: "upstream_password"}},
+		Secret:   &secret,
+		Nested:   &nested,
+	}
+
+	_, err := New(&Config{Object: config}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "password", config.TLS.KeyPassword)
+	assert.Equal(t, "upstream_password", config.Upstream.TLS.KeyPassword)
+	assert.Equal(t, "secret", *config.Secret)
+	assert.Equal(t, "nested_password", (*config.Nested).KeyPassword)
+}
+
+func TestObfuscatePointersInCollections(t *testing.T) {
+	config := struct {
+		Upstreams []*pointerUpstream           `json:"upstreams"`
+		Certs     map[string]*pointerTLSConfig `json:"certs"`
+	}{
+		Upstreams: []*pointerUpstream{
+			{Addr: "a:80", TLS: &pointerTLSConfig{KeyPassword: "a_password"}},
+			nil,
+		},
+		Certs: map[string]*pointerTLSConfig{
+			"main": {CertFile: "main.pem", KeyPassword: "main_password"},
+		},
+	}
+
+	viewer, err := New(&Config{Object: config}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "*REDACTED*", viewer.EnvNotation("upstreams.0.tls.key_password").Value)
+	assert.Equal(t, "", viewer.EnvNotation("upstreams.1.tls.key_password").Value)
+	assert.Contains(t, viewer.ParseEnvs(), "CERTS_MAIN_KEYPASSWORD=*REDACTED*")
+	assert.Equal(t, "a_password", config.Upstreams[0].TLS.KeyPassword)
+	assert.Equal(t, "main_password", config.Certs["main"].KeyPassword)
+}
+
+type recursiveNode struct {
+	Name     string                    `json:"name" default:"node"`
+	Next     *recursiveNode            `json:"next"`
+	Children map[string]*recursiveNode `json:"children"`
+}
+
+type recursiveService struct {
+	Name    string            `json:"name"`
+	Storage *recursiveStorage `json:"storage"`
+}
+
+type recursiveStorage struct {
+	Addr     string            `json:"addr"`
+	Fallback *recursiveService `json:"fallback"`
+}
+
+func TestRecursivePointerFields(t *testing.T) {
+	tcs := []struct {
+		testName     string
+		given        interface{}
+		expectedEnvs []string
+	}{
+		{
+			testName:     "self-referential nil pointer",
+			given:        struct{ Root recursiveNode }{Root: recursiveNode{Name: "root"}},
+			expectedEnvs: []string{"P_ROOT_NAME=root", "P_ROOT_NEXT=''"},
+		},
+		{
+			testName: "self-referential pointers",
+			given: struct{ Root recursiveNode }{Root: recursiveNode{
+				Name:     "root",
+				Next:     &recursiveNode{Name: "next"},
+				Children: map[string]*recursiveNode{"leaf": nil},
+			}},
+			expectedEnvs: []string{
+				"P_ROOT_NAME=root", "P_ROOT_NEXT_NAME=next", "P_ROOT_NEXT_NEXT=''", "P_ROOT_CHILDREN_LEAF=''",
+			},
+		},
+		{
+			testName:     "mutually recursive nil pointers",
+			given:        recursiveService{Name: "api"},
+			expectedEnvs: []string{"P_NAME=api", "P_STORAGE_ADDR=''", "P_STORAGE_FALLBACK=''"},
+		},
+		{
+			testName: "pointer cycles",
+			given:    cyclicConfig(),
+			expectedEnvs: []string{
+				"P_ROOT_NAME=root", "P_ROOT_NEXT_NAME=next", "P_ROOT_NEXT_NEXT=''", "P_ROOT_CHILDREN_SELF=''",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: tc.given}, "P_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.ElementsMatch(t, tc.expectedEnvs, viewer.ParseEnvs())
+		})
+	}
+}
+
+// cyclicConfig returns a configuration whose root node is pointed by the node following it and by its own children.
+func cyclicConfig() interface{} {
+	root := &recursiveNode{Name: "root"}
+	root.Next = &recursiveNode{Name: "next", Next: root}
+	root.Children = map[string]*recursiveNode{"self": root}
+
+	return struct {
+		Root *recursiveNode `json:"root"`
+	}{Root: root}
+}
+
+func TestPointerCycles(t *testing.T) {
+	viewer, err := New(&Config{Object: cyclicConfig()}, "P_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	rr := httptest.NewRecorder()
+	viewer.ConfigHandler(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t,
+		`{"root":{"name":"root","next":{"name":"next","next":null,"children":null},"children":{"self":null}}}`,
+		rr.Body.String())
+
+	assert.Empty(t, viewer.Validate())
+}
+
+func TestParsePointerComments(t *testing.T) {
+	viewer, err := New(&Config{Object: pointerConfig{}, Path: "./parser_test.go", ParseComments: true}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, "TLS is an optional TLS configuration.", viewer.configMap["TLS"].Description)
+	assert.Equal(t, "Timeout is an optional timeout.", viewer.JSONNotation("TIMEOUT").Description)
+	assert.Equal(t, "KeyPassword is the password of the TLS private key.",
+		viewer.JSONNotation("TLS_KEYPASSWORD").Description)
+}
+
+func TestMapOfStructs(t *testing.T) {
+	type entry struct {
+		ID int `json:"id"`
+	}
+
+	config := struct {
+		Metadata map[string]entry `json:"metadata"`
+	}{
+		Metadata: map[string]entry{
+			"key_99":  {ID: 99},
+			"key_100": {ID: 100},
+		},
+	}
+
+	tcs := []struct {
+		testName        string
+		mapKeyFormatter func(string) string
+		expectedEnvs    []string
+		expectedEnv     string
+	}{
+		{
+			testName:     "default map key formatter",
+			expectedEnvs: []string{"PREFIX_METADATA_KEY99_ID=99", "PREFIX_METADATA_KEY100_ID=100"},
+			expectedEnv:  "PREFIX_METADATA_KEY99_ID",
+		},
+		{
+			testName:        "custom map key formatter",
+			mapKeyFormatter: strings.ToUpper,
+			expectedEnvs:    []string{"PREFIX_METADATA_KEY_99_ID=99", "PREFIX_METADATA_KEY_100_ID=100"},
+			expectedEnv:     "PREFIX_METADATA_KEY_99_ID",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: config, MapKeyFormatter: tc.mapKeyFormatter}, "PREFIX_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.ElementsMatch(t, tc.expectedEnvs, viewer.ParseEnvs())
+
+			envVar := viewer.EnvNotation("metadata.key_99.id")
+			assert.Equal(t, tc.expectedEnv, envVar.Env)
+			assert.Equal(t, 99, envVar.Value)
+
+			metadata, ok := viewer.configMap["Metadata"].Value.(map[string]*EnvVar)
+			assert.True(t, ok)
+			assert.Len(t, metadata, 2)
+		})
+	}
+}
+
+func TestTypedValues(t *testing.T) {
+	port := 8080
+
+	config := struct {
+		Port     int           `json:"port"`
+		Enabled  bool          `json:"enabled"`
+		Timeout  time.Duration `json:"timeout"`
+		Name     string        `json:"name"`
+		Rate     float64       `json:"rate"`
+		Hosts    []string      `json:"hosts"`
+		NoHosts  []string      `json:"no_hosts"`
+		PortPtr  *int          `json:"port_ptr"`
+		NilPtr   *bool         `json:"nil_ptr"`
+		Mode     testMode      `json:"mode"`
+		Metadata map[string]int
+	}{
+		Port:     8080,
+		Enabled:  true,
+		Timeout:  time.Minute,
+		Name:     "name",
+		Rate:     0.5,
+		Hosts:    []string{"a", "b"},
+		PortPtr:  &port,
+		Mode:     "strict",
+		Metadata: map[string]int{"key": 1},
+	}
+
+	tcs := []struct {
+		configField      string
+		expectedValue    interface{}
+		expectedType     string
+		expectedEnvValue string
+	}{
+		{configField: "port", expectedValue: 8080, expectedType: "int", expectedEnvValue: "8080"},
+		{configField: "enabled", expectedValue: true, expectedType: "bool", expectedEnvValue: "true"},
+		{configField: "timeout", expectedValue: "1m0s", expectedType: "duration", expectedEnvValue: "1m0s"},
+		{configField: "name", expectedValue: "name", expectedType: "string", expectedEnvValue: "name"},
+		{configField: "rate", expectedValue: 0.5, expectedType: "float64", expectedEnvValue: "0.5"},
+		{configField: "hosts.1", expectedValue: "b", expectedType: "string", expectedEnvValue: "b"},
+		{configField: "no_hosts", expectedValue: []string(nil), expectedType: "[]string", expectedEnvValue: ""},
+		{configField: "port_ptr", expectedValue: 8080, expectedType: "int", expectedEnvValue: "8080"},
+		{configField: "nil_ptr", expectedValue: nil, expectedType: "bool", expectedEnvValue: ""},
+		{
+			configField:      "mode",
+			expectedValue:    testMode("strict"),
+			expectedType:     "structviewer.testMode",
+			expectedEnvValue: "strict",
+		},
+		{configField: "Metadata.key", expectedValue: 1, expectedType: "int", expectedEnvValue: "1"},
+	}
+
+	viewer, err := New(&Config{Object: config}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			envVar := viewer.EnvNotation(tc.configField)
+			assert.Equal(t, tc.expectedValue, envVar.Value)
+			assert.Equal(t, tc.expectedType, envVar.Type)
+			assert.Equal(t, tc.expectedEnvValue, envVar.EnvValue())
+		})
+	}
+
+	// Empty collections are exposed as a single environment variable.
+	assert.Equal(t, "no_hosts", viewer.JSONNotation("NOHOSTS").ConfigField)
+
+	envconfigViewer, err := New(&Config{Object: config, Naming: EnvconfigNaming}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	envVar := envconfigViewer.JSONNotation("HOSTS")
+	assert.Equal(t, []string{"a", "b"}, envVar.Value)
+	assert.Equal(t, "[]string", envVar.Type)
+	assert.Equal(t, "a,b", envVar.EnvValue())
+}
+
+type testMode string
+
+func TestEmbeddedStructs(t *testing.T) {
+	type TLS struct {
+		Enabled bool `json:"enabled"`
+	}
+
+	type Base struct {
+		LogLevel string `json:"log_level"`
+		Port     int    `json:"port"`
+		TLS      `json:"tls"`
+	}
+
+	type Common struct {
+		Base
+
+		Port  int    `json:"port"`
+		Name  string `json:"name"`
+		Label string
+	}
+
+	type Tagged struct {
+		Name string `json:"Name"`
+	}
+
+	type Untagged struct {
+		Name string
+	}
+
+	type Ambiguous struct {
+		// The yaml tag keeps go vet from reporting the conflict with Common.Name, which is the point of the test.
+		Name  string `yaml:"name"`
+		Label string
+	}
+
+	config := struct {
+		Common
+		*Tagged
+		*Untagged
+		Ambiguous
+		TLS
+
+		Secret string `json:"secret"`
+	}{
+		Common: Common{
+			Base:  Base{LogLevel: "debug", Port: 1, TLS: TLS{Enabled: true}},
+			Port:  8080,
+			Name:  "common",
+			Label: "common",
+		},
+		Tagged: &Tagged{Name: "tagged"},
+		Secret: "secret",
+	}
+
+	viewer, err := New(&Config{Object: config, TagKeys: []string{"json", "yaml"}}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	expectedEnvs := []string{
+		"LOGLEVEL=debug",
+		"TLS_ENABLED=true",
+		"PORT=8080",
+		"SECRET=secret",
+		"ENABLED=false",
+		"NAME=tagged",
+	}
+	assert.ElementsMatch(t, expectedEnvs, viewer.ParseEnvs())
+
+	tcs := []struct {
+		testName      string
+		configField   string
+		expectedEnv   string
+		expectedValue interface{}
+	}{
+		{testName: "promoted field", configField: "log_level", expectedEnv: "LOGLEVEL", expectedValue: "debug"},
+		{testName: "shadowed field", configField: "port", expectedEnv: "PORT", expectedValue: 8080},
+		{testName: "tagged embedded struct", configField: "tls.enabled", expectedEnv: "TLS_ENABLED", expectedValue: true},
+		{testName: "nil embedded pointer", configField: "enabled", expectedEnv: "ENABLED", expectedValue: false},
+		{testName: "tagged field wins", configField: "Name", expectedEnv: "NAME", expectedValue: "tagged"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			envVar := viewer.EnvNotation(tc.configField)
+			assert.Equal(t, tc.expectedEnv, envVar.Env)
+			assert.Equal(t, tc.expectedValue, envVar.Value)
+		})
+	}
+
+	t.Run("ambiguous fields", func(t *testing.T) {
+		// Common.Name and Ambiguous.Name are tagged at the same depth, so they are both dropped.
+		assert.Empty(t, viewer.EnvNotation("name").Env)
+		// Common.Label and Ambiguous.Label are untagged at the same depth, so they are both dropped.
+		assert.Empty(t, viewer.EnvNotation("Label").Env)
+	})
+
+	t.Run("conflicting environment variables", func(t *testing.T) {
+		type Key struct {
+			APIKey string `json:"api_key"`
+		}
+
+		type OtherKey struct {
+			Apikey string `json:"apikey"`
+		}
+
+		conflicting := struct {
+			Base
+			Key
+			OtherKey
+
+			Level      string `json:"loglevel"`
+			ListenPort int    `json:"listen_port"`
+			Listenport int    `json:"listenport"`
+		}{
+			Base:       Base{LogLevel: "debug"},
+			Key:        Key{APIKey: "key"},
+			OtherKey:   OtherKey{Apikey: "other"},
+			Level:      "info",
+			ListenPort: 8080,
+			Listenport: 9090,
+		}
+
+		viewer, err := New(&Config{Object: conflicting}, "")
+		assert.NoError(t, err, "failed to instantiate viewer")
+
+		// Level and Base.LogLevel share LOGLEVEL, and the least nested one wins.
+		assert.Equal(t, "info", viewer.JSONNotation("LOGLEVEL").Value)
+		assert.Empty(t, viewer.EnvNotation("log_level").Env)
+		// Key.APIKey and OtherKey.Apikey are tagged at the same depth and share APIKEY, so they are both dropped.
+		assert.Empty(t, viewer.JSONNotation("APIKEY").ConfigField)
+		// ListenPort and Listenport are declared by the struct itself, so they are both kept as encoding/json does.
+		assert.Equal(t, 8080, viewer.EnvNotation("listen_port").Value)
+		assert.Equal(t, 9090, viewer.EnvNotation("listenport").Value)
+		assert.ElementsMatch(t, []string{
+			"LOGLEVEL=info", "PORT=0", "TLS_ENABLED=false", "LISTENPORT=8080", "LISTENPORT=9090",
+		}, viewer.ParseEnvs())
+	})
+}
+
+type storageConfig interface {
+	storageType() string
+}
+
+type redisStorage struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password" structviewer:"obfuscate"`
+}
+
+func (*redisStorage) storageType() string {
+	return "redis"
+}
+
+func TestInterfaceFields(t *testing.T) {
+	storage := &redisStorage{Addr: "localhost:6379", Password: "secret"}
+
+	config := struct {
+		Storage  storageConfig          `json:"storage"`
+		Backend  interface{}            `json:"backend"`
+		Secret   any                    `json:"secret" structviewer:"obfuscate"`
+		Port     any                    `json:"port" structviewer:"obfuscate"`
+		Timeout  interface{}            `json:"timeout"`
+		Options  interface{}            `json:"options"`
+		Backends map[string]interface{} `json:"backends"`
+		Nil      interface{}            `json:"nil"`
+	}{
+		Storage:  storage,
+		Backend:  redisStorage{Addr: "localhost:6380", Password: "secret"},
+		Secret:   "secret",
+		Port:     8080,
+		Timeout:  time.Minute,
+		Options:  []interface{}{"a", 1},
+		Backends: map[string]interface{}{"redis": &redisStorage{Addr: "localhost:6381", Password: "secret"}},
+	}
+
+	viewer, err := New(&Config{Object: config}, "")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		configField   string
+		expectedEnv   string
+		expectedValue interface{}
+		expectedType  string
+	}{
+		{configField: "storage.addr", expectedEnv: "STORAGE_ADDR", expectedValue: "localhost:6379", expectedType: "string"},
+		{
+			configField:   "storage.password",
+			expectedEnv:   "STORAGE_PASSWORD",
+			expectedValue: "*REDACTED*",
+			expectedType:  "string",
+		},
+		{configField: "backend.addr", expectedEnv: "BACKEND_ADDR", expectedValue: "localhost:6380", expectedType: "string"},
+		{
+			configField:   "backend.password",
+			expectedEnv:   "BACKEND_PASSWORD",
+			expectedValue: "*REDACTED*",
+			expectedType:  "string",
+		},
+		{configField: "secret", expectedEnv: "SECRET", expectedValue: "*REDACTED*", expectedType: "string"},
+		{configField: "port", expectedEnv: "PORT", expectedValue: "*REDACTED*", expectedType: "int"},
+		{configField: "timeout", expectedEnv: "TIMEOUT", expectedValue: "1m0s", expectedType: "duration"},
+		{configField: "options.1", expectedEnv: "OPTIONS_1", expectedValue: 1, expectedType: "int"},
+		{
+			configField:   "backends.redis.password",
+			expectedEnv:   "BACKENDS_REDIS_PASSWORD",
+			expectedValue: "*REDACTED*",
+			expectedType:  "string",
+		},
+		{configField: "nil", expectedEnv: "NIL", expectedValue: nil, expectedType: "interface {}"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			envVar := viewer.EnvNotation(tc.configField)
+			assert.Equal(t, tc.expectedEnv, envVar.Env)
+			assert.Equal(t, tc.expectedValue, envVar.Value)
+			assert.Equal(t, tc.expectedType, envVar.Type)
+		})
+	}
+
+	t.Run("concrete type of structs", func(t *testing.T) {
+		assert.Equal(t, "structviewer.redisStorage", viewer.configMap["Storage"].Type)
+		assert.Equal(t, "structviewer.redisStorage", viewer.configMap["Backend"].Type)
+
+		backends, ok := viewer.configMap["Backends"].Value.(map[string]*EnvVar)
+		assert.True(t, ok)
+		assert.Equal(t, "structviewer.redisStorage", backends["redis"].Type)
+	})
+
+	t.Run("original config is not modified", func(t *testing.T) {
+		assert.Equal(t, "secret", storage.Password)
+		assert.Equal(t, "secret", config.Backend.(redisStorage).Password)
+		assert.Equal(t, "secret", config.Backends["redis"].(*redisStorage).Password)
+	})
+}
+
+func TestObfuscateCollectionsInMaps(t *testing.T) {
+	type group struct {
+		Name  string `json:"name"`
+		Token string `json:"token" structviewer:"obfuscate"`
+	}
+
+	config := struct {
+		Groups map[string][]group          `json:"groups"`
+		Nested map[string]map[string]group `json:"nested"`
+	}{
+		Groups: map[string][]group{"g": {{Name: "a", Token: "secret1"}}},
+		Nested: map[string]map[string]group{"n": {"b": {Name: "b", Token: "secret2"}}},
+	}
+
+	viewer, err := New(&Config{Object: config}, "P_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	envs := viewer.ParseEnvs()
+	assert.Contains(t, envs, "P_GROUPS_G_0_TOKEN=*REDACTED*")
+	assert.Contains(t, envs, "P_GROUPS_G_0_NAME=a")
+	assert.NotContains(t, strings.Join(envs, "\n"), "secret")
+
+	req, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(viewer.ConfigHandler).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "secret")
+	assert.Equal(t, "secret1", config.Groups["g"][0].Token)
+	assert.Equal(t, "secret2", config.Nested["n"]["b"].Token)
+}
+
+func TestObfuscateMapsInSlices(t *testing.T) {
+	config := struct {
+		Vaults map[string][]map[string]string `json:"vaults" structviewer:"obfuscate_values"`
+		Any    []interface{}                  `json:"any"`
+	}{
+		Vaults: map[string][]map[string]string{"eu": {{"token": "vault-token"}}},
+		Any:    []interface{}{map[string]string{"k": "v"}},
+	}
+
+	viewer, err := New(&Config{Object: config}, "P_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.ElementsMatch(t, []string{"P_VAULTS_EU_0_TOKEN=*REDACTED*", "P_ANY_0_K=v"}, viewer.ParseEnvs())
+
+	envVar := viewer.EnvNotation("vaults.eu.0.token")
+	assert.Equal(t, "P_VAULTS_EU_0_TOKEN", envVar.Env)
+	assert.Equal(t, getPointerBool(true), envVar.Obfuscated)
+	assert.Equal(t, "P_ANY_0_K", viewer.EnvNotation("any.0.k").Env)
+	assert.Equal(t, "vault-token", config.Vaults["eu"][0]["token"])
+}
@@ -0,0 +1,112 @@
+package structviewer
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ValueSource is the source a configuration value comes from.
+type ValueSource string
+
+// Sources of the configuration values.
+const (
+	// EnvSource is the source of the values set by an environment variable of Config.Environ.
+	EnvSource ValueSource = "env"
+	// FileSource is the source of the values set by Config.File.
+	FileSource ValueSource = "file"
+	// DefaultSource is the source of the values set by neither an environment variable nor the file.
+	DefaultSource ValueSource = "default"
+)
+
+// ProcessEnviron returns the environment of the process as a map, to be used as Config.Environ.
+func ProcessEnviron() map[string]string {
+	environ := map[string]string{}
+
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			environ[k] = v
+		}
+	}
+
+	return environ
+}
+
+// annotateSources sets the source of the values of the fields, if Config.Environ or Config.File is given. Values set
+// by an environment variable take precedence over the ones set by the file, as configuration loaders usually do.
+func (v *Viewer) annotateSources() {
+	if v.environ == nil && v.configFile == nil {
+		return
+	}
+
+	v.annotateEnvSources(v.envs, nil, v.configFile)
+}
+
+// annotateEnvSources sets the source of the given EnvVar. collectionEnvs are the environment variables setting the
+// collections holding the EnvVar as a whole, from the closest one, and fileNode is the node of the decoded
+// configuration file holding the EnvVar, if any.
+func (v *Viewer) annotateEnvSources(envs []*EnvVar, collectionEnvs []string, fileNode interface{}) {
+	for _, env := range envs {
+		child, inFile := fileChild(fileNode, env.segment)
+
+		if nested, ok := env.Value.(map[string]*EnvVar); ok && env.isStruct {
+			nestedCollectionEnvs := collectionEnvs
+			if env.collectionEnv != "" {
+				nestedCollectionEnvs = append([]string{env.collectionEnv}, collectionEnvs...)
+			}
+
+			for _, nestedEnv := range nested {
+				v.annotateEnvSources([]*EnvVar{nestedEnv}, nestedCollectionEnvs, child)
+			}
+
+			continue
+		}
+
+		env.Source, env.SourceEnv = v.source(env, collectionEnvs, inFile)
+	}
+}
+
+// source returns the source of the value of the given EnvVar and, for EnvSource, the environment variable setting it.
+// inFile reports whether the decoded configuration file sets the value.
+func (v *Viewer) source(env *EnvVar, collectionEnvs []string, inFile bool) (ValueSource, string) {
+	for _, name := range append([]string{env.Env}, collectionEnvs...) {
+		if _, ok := v.environ[name]; ok && name != "" {
+			return EnvSource, name
+		}
+	}
+
+	if inFile {
+		return FileSource, ""
+	}
+
+	return DefaultSource, ""
+}
+
+// fileChild returns the child of the given node of a decoded configuration file with the given key or index, matching
+// the keys case-insensitively if they don't match exactly. The segment is a single key, even if it holds dots.
+func fileChild(node interface{}, segment string) (interface{}, bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if child, ok := n[segment]; ok {
+			return child, true
+		}
+
+		for key, child := range n {
+			if strings.EqualFold(key, segment) {
+				return child, true
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, child := range n {
+			if k, ok := key.(string); ok && strings.EqualFold(k, segment) {
+				return child, true
+			}
+		}
+	case []interface{}:
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(n) {
+			return n[i], true
+		}
+	}
+
+	return nil, false
+}
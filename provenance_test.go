@@ -0,0 +1,92 @@
+package structviewer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testProvenanceConfig struct {
+	ListenPort int                   `json:"listen_port"`
+	Storage    testProvenanceStorage `json:"storage"`
+	Tags       []string              `json:"tags"`
+	Headers    map[string]string     `json:"headers"`
+	Debug      bool                  `json:"debug"`
+}
+
+type testProvenanceStorage struct {
+	Addr  string `json:"addr"`
+	Token string `json:"token"`
+}
+
+func TestValueSources(t *testing.T) {
+	config := testProvenanceConfig{
+		ListenPort: 9090,
+		Storage:    testProvenanceStorage{Addr: "redis:6379", Token: "token"},
+		Tags:       []string{"a", "b"},
+		Headers:    map[string]string{"X-Env": "prod", "api.example.com": "eu"},
+	}
+
+	viewer, err := New(&Config{
+		Object: config,
+		Environ: map[string]string{
+			"APP_LISTENPORT": "9090",
+			"APP_TAGS":       "a,b",
+			"OTHER":          "other",
+		},
+		File: []byte(`{"listen_port": 8080, "storage": {"addr": "redis:6379"},
+			"Headers": {"x-env": "prod", "api.example.com": "eu"}}`),
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		configField       string
+		expectedSource    ValueSource
+		expectedSourceEnv string
+	}{
+		{configField: "listen_port", expectedSource: EnvSource, expectedSourceEnv: "APP_LISTENPORT"},
+		{configField: "storage.addr", expectedSource: FileSource},
+		{configField: "storage.token", expectedSource: DefaultSource},
+		{configField: "tags.1", expectedSource: EnvSource, expectedSourceEnv: "APP_TAGS"},
+		{configField: "headers.X-Env", expectedSource: FileSource},
+		{configField: "headers.api.example.com", expectedSource: FileSource},
+		{configField: "debug", expectedSource: DefaultSource},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.configField, func(t *testing.T) {
+			envVar := viewer.EnvNotation(tc.configField)
+			assert.Equal(t, tc.expectedSource, envVar.Source)
+			assert.Equal(t, tc.expectedSourceEnv, envVar.SourceEnv)
+		})
+	}
+}
+
+func TestValueSourcesDetailedConfigHandler(t *testing.T) {
+	viewer, err := New(&Config{
+		Object:  struct{ Port int }{Port: 8080},
+		Environ: map[string]string{"APP_PORT": "8080"},
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	req, err := http.NewRequest("GET", "/detailed-config", nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(viewer.DetailedConfigHandler).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"Port": {"config_field": "Port", "env": "APP_PORT", "value": 8080, "type": "int",
+		"obfuscated": false, "source": "env", "source_env": "APP_PORT"}}`, rr.Body.String())
+}
+
+func TestValueSourcesWithoutInputs(t *testing.T) {
+	viewer, err := New(&Config{Object: struct{ Port int }{Port: 8080}}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+	assert.Empty(t, viewer.EnvNotation("Port").Source)
+
+	_, err = New(&Config{Object: struct{ Port int }{}, File: []byte("{")}, "APP_")
+	assert.ErrorContains(t, err, "invalid configuration file")
+}
@@ -0,0 +1,159 @@
+package structviewer
+
+import (
+	"sort"
+	"strings"
+)
+
+// RedactionReason is the kind of rule masking a field, or revealing a field which looks like a secret.
+type RedactionReason string
+
+// Reasons of the redaction report.
+const (
+	// TagRedactionReason is the reason of the fields masked or revealed by their structviewer tag.
+	TagRedactionReason RedactionReason = "tag"
+	// PatternRedactionReason is the reason of the fields masked by Config.Redaction, or looking like secrets because
+	// they match DefaultRedactionPatterns.
+	PatternRedactionReason RedactionReason = "pattern"
+	// TypeRedactionReason is the reason of the fields masked because their values are URLs or DSNs with credentials.
+	TypeRedactionReason RedactionReason = "type"
+)
+
+// redactionRule is the rule masking or revealing a field, as reported by RedactionReport.
+type redactionRule struct {
+	reason RedactionReason
+	// rule is the tag, the pattern or the redaction mode of the rule.
+	rule string
+}
+
+// typeRule is the rule of the URLs and DSNs holding credentials.
+var typeRule = redactionRule{reason: TypeRedactionReason, rule: URLRedactionMode}
+
+// tagRule returns the rule of the structviewer tag of the given field.
+func tagRule(field Field) redactionRule {
+	return redactionRule{reason: TagRedactionReason, rule: field.Tag.Get(StructViewerTag)}
+}
+
+// RedactionReport lists the masked fields of a Viewer, and the fields which look like secrets but are not masked.
+type RedactionReport struct {
+	// Audience is the audience of the Viewer, see Viewer.View.
+	Audience string `json:"audience"`
+	// Fields are the reported fields, sorted by their environment variable notation.
+	Fields []RedactionReportField `json:"fields"`
+}
+
+// RedactionReportField is a field of the redaction report.
+type RedactionReportField struct {
+	// Path is the JSON notation of the field.
+	Path string `json:"path"`
+	// Env is the environment variable notation of the field.
+	Env string `json:"env"`
+	// Rule is the structviewer tag, the pattern or the redaction mode masking or revealing the field, e.g.
+	// 'obfuscate,mode=last4', '*password*' or 'url'.
+	Rule string `json:"rule"`
+	// Masked represents whether the field is masked.
+	Masked bool `json:"masked"`
+	// Reason is the kind of Rule.
+	Reason RedactionReason `json:"reason"`
+}
+
+// Unmasked returns the fields of the report which look like secrets but are neither masked nor explicitly revealed
+// with their structviewer tag, e.g. `structviewer:"public"`.
+func (r RedactionReport) Unmasked() []RedactionReportField {
+	var unmasked []RedactionReportField
+
+	for _, field := range r.Fields {
+		if !field.Masked && field.Reason == PatternRedactionReason {
+			unmasked = append(unmasked, field)
+		}
+	}
+
+	return unmasked
+}
+
+// RedactionReport returns the fields masked by the Viewer along with the rule masking them, and the fields which look
+// like secrets but are not masked. Fields look like secrets if they match the patterns of Config.Redaction or
+// DefaultRedactionPatterns.
+func (v *Viewer) RedactionReport() RedactionReport {
+	report := RedactionReport{Audience: v.audience, Fields: []RedactionReportField{}}
+
+	v.reportFields(&report, v.envs)
+
+	sort.Slice(report.Fields, func(i, j int) bool {
+		return report.Fields[i].Env < report.Fields[j].Env
+	})
+
+	return report
+}
+
+// reportFields adds the masked fields and the fields looking like secrets among the given ones to the given report.
+func (v *Viewer) reportFields(report *RedactionReport, envs []*EnvVar) {
+	for _, env := range envs {
+		if _, ok := v.masks[env.goPath]; ok {
+			rule := v.rules[env.goPath]
+
+			report.Fields = append(report.Fields, RedactionReportField{
+				Path:   env.ConfigField,
+				Env:    env.Env,
+				Rule:   rule.rule,
+				Masked: true,
+				Reason: rule.reason,
+			})
+
+			continue
+		}
+
+		if env.isStruct {
+			nested, _ := env.Value.(map[string]*EnvVar)
+			for _, nestedEnv := range nested {
+				v.reportFields(report, []*EnvVar{nestedEnv})
+			}
+
+			continue
+		}
+
+		pattern, ok := v.secretPattern(env)
+		if !ok {
+			continue
+		}
+
+		rule, ok := v.revealingRule(env.goPath)
+		if !ok {
+			rule = redactionRule{reason: PatternRedactionReason, rule: pattern}
+		}
+
+		report.Fields = append(report.Fields, RedactionReportField{
+			Path:   env.ConfigField,
+			Env:    env.Env,
+			Rule:   rule.rule,
+			Reason: rule.reason,
+		})
+	}
+}
+
+// secretPattern returns the pattern matched by the given field if it looks like a secret.
+func (v *Viewer) secretPattern(env *EnvVar) (string, bool) {
+	name := env.goPath[strings.LastIndex(env.goPath, ".")+1:]
+
+	if pattern, ok := v.redaction.match(name, env.ConfigField, env.Env); ok {
+		return pattern, true
+	}
+
+	return (&RedactionPolicy{}).match(name, env.ConfigField, env.Env)
+}
+
+// revealingRule returns the rule revealing the value with the given Go path, or one of its parents.
+func (v *Viewer) revealingRule(goPath string) (redactionRule, bool) {
+	for {
+		if rule, ok := v.rules[goPath]; ok {
+			return rule, true
+		}
+
+		i := strings.LastIndex(goPath, ".")
+		if i < 0 {
+			return redactionRule{}, false
+		}
+
+		goPath = goPath[:i]
+	}
+}
@@ -1,14 +1,19 @@
 package structviewer
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"go/ast"
+	"net/http"
 	"reflect"
 )
 
 // Viewer is the pkg control structure where the prefix and env vars are stored.
 type Viewer struct {
-	// config is the configuration structure.
+	// original is the unredacted copy of the configuration structure.
+	original interface{}
+	// config is the configuration structure, redacted for the audience of the Viewer.
 	config interface{}
 	// prefix is the prefix of the environment variables.
 	prefix string
@@ -21,8 +26,44 @@ type Viewer struct {
 	// configMap is the map representation of the configuration structure.
 	// It is used to expose the configuration structure as JSON in JSONHandler.
 	configMap map[string]*EnvVar
-	// file is the ast.File of the configuration structure.
-	file *ast.File
+	// files are the parsed source files of the configuration structure, indexed by their package path.
+	files map[string][]*ast.File
+	// naming is the strategy used to generate environment variable and JSON notations.
+	naming NamingStrategy
+	// tagKeys is the ordered list of struct tag keys used to resolve the names of the fields.
+	tagKeys []string
+	// redaction is the policy masking the fields which are not tagged for obfuscation.
+	redaction *RedactionPolicy
+	// redactors are the redactors of the obfuscated fields, indexed by their mode.
+	redactors map[string]Redactor
+	// masks are the masked representations of the obfuscated values, indexed by their Go path.
+	masks map[string]string
+	// copying holds the pointers of the configuration whose values are being copied while it is obfuscated, including
+	// the nil pointers whose zero values are walked, so that pointer cycles and recursive types are not walked endlessly.
+	copying map[pointerKey]bool
+	// rules are the rules masking the obfuscated values, or revealing the values which would otherwise be reported as
+	// unmasked secrets, indexed by their Go path.
+	rules map[string]redactionRule
+	// audience is the audience the configuration is redacted for.
+	audience string
+	// audiences are the audiences of the configuration, from the least to the most privileged.
+	audiences []string
+	// audienceFunc returns the audience of the caller of the HTTP handlers.
+	audienceFunc func(r *http.Request) string
+	// views are the projections of the configuration exposed to each audience.
+	views map[string]*Viewer
+	// descriptionSources is the precedence order of the sources of the descriptions of the fields.
+	descriptionSources []DescriptionSource
+	// defaults is a pointer to the default configuration structure.
+	defaults interface{}
+	// hasDefaults represents whether defaults is a copy of Config.Defaults.
+	hasDefaults bool
+	// defaultTags are the Go paths of the fields tagged with `default:"..."`.
+	defaultTags map[string]bool
+	// environ is the environment the configuration is loaded from, indexed by variable name.
+	environ map[string]string
+	// configFile is the decoded configuration file the configuration is loaded from.
+	configFile interface{}
 }
 
 var (
@@ -32,6 +73,15 @@ var (
 	ErrEmptyStruct = errors.New("empty Struct in configuration")
 	// ErrInvalidObjectType is returned when config.Object is not a struct.
 	ErrInvalidObjectType = errors.New("invalid object type")
+	// ErrUnknownRedactionMode is returned when a field is tagged with a redaction mode that is not registered.
+	ErrUnknownRedactionMode = errors.New("unknown redaction mode")
+	// ErrMissingFingerprintSalt is returned when a field is tagged with the fingerprint redaction mode while
+	// Config.FingerprintSalt is empty.
+	ErrMissingFingerprintSalt = errors.New("missing fingerprint salt")
+	// ErrUnknownAudience is returned when an audience is not one of Config.Audiences.
+	ErrUnknownAudience = errors.New("unknown audience")
+	// ErrUnknownDescriptionSource is returned when Config.DescriptionSources includes an unknown source.
+	ErrUnknownDescriptionSource = errors.New("unknown description source")
 )
 
 const StructViewerTag = "structviewer"
@@ -42,12 +92,78 @@ type Config struct {
 	Object interface{}
 
 	// ParseComments decides parsing comments of given Object or not. If it is set to false,
-	// the comment parser skips parsing comments of given Object.
+	// the comment parser skips parsing comments of given Object. Descriptions registered with
+	// RegisterFieldDocs are used either way, and comments override them when the sources are available.
 	ParseComments bool
 
-	// Path is the file path of the Object. Needed for comment parser.
+	// Path is the file path of the Object. Needed for comment parser. It can also be the directory of the package
+	// declaring the Object, a glob of its files, e.g. "./config/*.go", or its import path. Test files are ignored
+	// unless they are given explicitly. The packages declaring the struct types referenced from the Object are
+	// parsed as well, if their sources are available.
 	// Default value is "./config.go".
 	Path string
+
+	// MapKeyFormatter converts map keys into their environment variable notation, e.g. the 'key_99' entry of the
+	// 'metadata' map is exposed as 'METADATA_KEY99' by default. If set, it overrides the map key notation of Naming.
+	MapKeyFormatter func(key string) string
+
+	// Naming is the strategy used to generate the environment variable and JSON notations of the fields, map keys
+	// and slice indexes. Use EnvconfigNaming if the configuration is loaded with github.com/kelseyhightower/envconfig,
+	// or ViperNaming if it is loaded with github.com/spf13/viper.
+	// Default value is DefaultNaming.
+	Naming NamingStrategy
+
+	// TagKeys is the ordered list of struct tag keys used to resolve the names of the fields in the configuration
+	// file, e.g. []string{"mapstructure", "yaml"}. The first tag found on a field is used, the others are fallbacks.
+	// Default value is []string{"json"}.
+	TagKeys []string
+
+	// Redaction masks the fields whose names match its patterns, in addition to the fields tagged with
+	// `structviewer:"obfuscate"`. Use &RedactionPolicy{} to mask the fields matching DefaultRedactionPatterns.
+	// If nil, only the tagged fields are masked.
+	Redaction *RedactionPolicy
+
+	// Redactors are the custom redaction modes, selected with the structviewer tag of the fields, e.g.
+	// `structviewer:"obfuscate,mode=custom"`. They override the built-in modes with the same name.
+	Redactors map[string]Redactor
+
+	// FingerprintSalt is the salt of the fingerprints of the 'fingerprint' redaction mode. It must be the same on the
+	// nodes whose fingerprints are compared, and kept secret to prevent guessing short values from their fingerprint.
+	// New returns ErrMissingFingerprintSalt if a field uses the built-in fingerprint mode while it is empty.
+	FingerprintSalt string
+
+	// Audiences are the audiences of the configuration, from the least to the most privileged. Fields tagged with
+	// `structviewer:"audience=admin"` are only revealed to the 'admin' audience and the more privileged ones.
+	// Default value is DefaultAudiences.
+	Audiences []string
+
+	// AudienceFunc returns the audience of the caller of the HTTP handlers, e.g. from the claims set by an
+	// authentication middleware. If nil, or if it returns an unknown audience, the handlers expose the configuration
+	// to the least privileged audience.
+	AudienceFunc func(r *http.Request) string
+
+	// DescriptionSources is the precedence order of the sources of the descriptions of the fields: their tags, their
+	// doc comments and their trailing line comments. Sources which are not listed are ignored.
+	// Default value is DefaultDescriptionSources.
+	DescriptionSources []DescriptionSource
+
+	// Defaults is an instance of the type of Object holding the default values of the configuration, e.g. the
+	// configuration before the file and the environment variables are loaded. If nil, the default values are read from
+	// the `default:"..."` tags of the fields, as github.com/kelseyhightower/envconfig does.
+	Defaults interface{}
+
+	// Environ is the environment the Object is loaded from, e.g. ProcessEnviron(), used to find which fields are set by
+	// an environment variable. See EnvVar.Source.
+	Environ map[string]string
+
+	// File is the content of the configuration file the Object is loaded from, used to find which fields are set by
+	// the file. See EnvVar.Source.
+	File []byte
+
+	// FileUnmarshal decodes File, e.g. yaml.Unmarshal for YAML files. Keys are matched against the JSON notation of
+	// the fields.
+	// Default value is json.Unmarshal.
+	FileUnmarshal func(data []byte, v interface{}) error
 }
 
 // New receives a configuration structure and a prefix and returns a Viewer struct to manipulate this library.
@@ -82,27 +198,105 @@ func New(config *Config, prefix string) (*Viewer, error) {
 		config.Path = "./config.go"
 	}
 
-	cfg := Viewer{config: objectCopy, prefix: prefix, confFilePath: config.Path}
-	err := cfg.start(config.ParseComments)
+	if config.Naming == nil {
+		config.Naming = DefaultNaming
+	}
+
+	if len(config.TagKeys) == 0 {
+		config.TagKeys = []string{"json"}
+	}
+
+	if config.Redaction != nil {
+		if err := config.Redaction.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.Audiences) == 0 {
+		config.Audiences = DefaultAudiences
+	}
+
+	if len(config.DescriptionSources) == 0 {
+		config.DescriptionSources = DefaultDescriptionSources
+	}
+
+	if err := validateDescriptionSources(config.DescriptionSources); err != nil {
+		return nil, err
+	}
+
+	defaults, defaultTags, err := newDefaults(reflect.TypeOf(objectCopy).Elem(), config.Defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	var file interface{}
+
+	if config.File != nil {
+		if config.FileUnmarshal == nil {
+			config.FileUnmarshal = json.Unmarshal
+		}
+
+		if err := config.FileUnmarshal(config.File, &file); err != nil {
+			return nil, fmt.Errorf("invalid configuration file: %w", err)
+		}
+	}
+
+	naming := config.Naming
+	if config.MapKeyFormatter != nil {
+		naming = mapKeyNaming{NamingStrategy: naming, format: config.MapKeyFormatter}
+	}
+
+	cfg := Viewer{
+		original:           objectCopy,
+		prefix:             prefix,
+		confFilePath:       config.Path,
+		naming:             naming,
+		tagKeys:            config.TagKeys,
+		redaction:          config.Redaction,
+		redactors:          newRedactors(config.Redactors, config.FingerprintSalt),
+		masks:              map[string]string{},
+		rules:              map[string]redactionRule{},
+		audience:           config.Audiences[0],
+		audiences:          config.Audiences,
+		audienceFunc:       config.AudienceFunc,
+		descriptionSources: config.DescriptionSources,
+		defaults:           defaults,
+		hasDefaults:        config.Defaults != nil,
+		defaultTags:        defaultTags,
+		environ:            config.Environ,
+		configFile:         file,
+	}
+
+	err = cfg.start()
+	if err != nil {
+		return &cfg, err
+	}
+
+	err = cfg.startViews()
+	if err != nil {
+		return &cfg, err
+	}
+
+	err = cfg.describeViews(config.ParseComments)
 
 	return &cfg, err
 }
 
 // Start starts the Viewer control struct, parsing the environment variables
-func (v *Viewer) start(parseComments bool) error {
+func (v *Viewer) start() error {
 	var err error
 
-	v.config, err = obfuscateTags(v.config)
+	v.copying = map[pointerKey]bool{}
+	v.config, err = v.obfuscateTags(copyObject(v.original), "", v.prefix, "")
+	v.copying = nil
+
 	if err != nil {
 		return err
 	}
 
-	v.envs = parseEnvs(v.config, v.prefix, "")
-	if parseComments {
-		if err = v.parseComments(); err != nil {
-			return err
-		}
-	}
+	v.envs = v.parseEnvs(v.config, v.prefix, "", "")
+	v.annotateDefaults()
+	v.annotateSources()
 
 	v.configMap = parseConfig(v.envs)
 
@@ -0,0 +1,22 @@
+// Package structviewertest provides test helpers for the configurations exposed with structviewer.
+package structviewertest
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/structviewer"
+)
+
+// AssertRedacted fails the test if fields of the given Viewer look like secrets but are not masked, see
+// structviewer.RedactionReport.Unmasked. Fields which are not secrets can be revealed with `structviewer:"public"`.
+// It reports whether the assertion succeeded.
+func AssertRedacted(t testing.TB, v *structviewer.Viewer) bool {
+	t.Helper()
+
+	unmasked := v.RedactionReport().Unmasked()
+	for _, field := range unmasked {
+		t.Errorf("field %s (%s) matches the secret pattern %q but is not masked", field.Path, field.Env, field.Rule)
+	}
+
+	return len(unmasked) == 0
+}
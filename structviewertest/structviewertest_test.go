@@ -0,0 +1,61 @@
+package structviewertest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/structviewer"
+)
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRedacted(t *testing.T) {
+	tcs := []struct {
+		testName       string
+		givenObject    interface{}
+		expectedErrors []string
+	}{
+		{
+			testName: "masked secrets",
+			givenObject: struct {
+				Password string `json:"password" structviewer:"obfuscate"`
+				Port     int    `json:"port"`
+			}{Password: "password", Port: 8080},
+		},
+		{
+			testName: "public secret",
+			givenObject: struct {
+				TokenURL string `json:"token_url" structviewer:"public"`
+			}{TokenURL: "https://example.com/token"},
+		},
+		{
+			testName: "unmasked secret",
+			givenObject: struct {
+				APIToken string `json:"api_token"`
+			}{APIToken: "token"},
+			expectedErrors: []string{`field api_token (APP_APITOKEN) matches the secret pattern "*token*" but is not masked`},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			v, err := structviewer.New(&structviewer.Config{Object: tc.givenObject}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			r := &recorder{TB: t}
+			assert.Equal(t, len(tc.expectedErrors) == 0, AssertRedacted(r, v))
+			assert.Equal(t, tc.expectedErrors, r.errors)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package structviewer
+
+import (
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions of an unknown environment variable.
+const maxSuggestions = 3
+
+// UnknownEnv is an environment variable with the prefix of the Viewer which matches no field, e.g. a misspelled one.
+type UnknownEnv struct {
+	// Name is the name of the environment variable.
+	Name string `json:"name"`
+	// Suggestions are the closest valid environment variables, from the closest one.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// UnknownEnvs returns the variables of the given environment, in the 'KEY=value' form of os.Environ, which have the
+// prefix of the Viewer but match no field, along with suggestions of the valid variables closest to them. Variables
+// setting new entries of expanded maps and slices are valid. If the Viewer has no prefix, no variable is reported.
+func (v *Viewer) UnknownEnvs(environ []string) []UnknownEnv {
+	unknown := []UnknownEnv{}
+
+	if v.prefix == "" {
+		return unknown
+	}
+
+	known, collections := map[string]bool{}, []string{}
+	v.knownEnvs(v.envs, known, &collections)
+
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, v.prefix) || known[name] || hasCollectionPrefix(name, collections, v.naming) {
+			continue
+		}
+
+		unknown = append(unknown, UnknownEnv{Name: name, Suggestions: suggestions(name, known)})
+	}
+
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Name < unknown[j].Name
+	})
+
+	return unknown
+}
+
+// WarnUnknownEnvs logs a warning with the given logger, or slog.Default() if nil, for each unknown environment
+// variable of the given environment, e.g. at startup. It returns the unknown environment variables.
+func (v *Viewer) WarnUnknownEnvs(logger *slog.Logger, environ []string) []UnknownEnv {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	unknown := v.UnknownEnvs(environ)
+	for _, env := range unknown {
+		logger.Warn("unknown environment variable", slog.String("env", env.Name),
+			slog.Any("suggestions", env.Suggestions))
+	}
+
+	return unknown
+}
+
+// environList returns Config.Environ in the form of os.Environ, or the environment of the process if it is not
+// given.
+func (v *Viewer) environList() []string {
+	if v.environ == nil {
+		return os.Environ()
+	}
+
+	environ := make([]string, 0, len(v.environ))
+	for name, value := range v.environ {
+		environ = append(environ, name+"="+value)
+	}
+
+	return environ
+}
+
+// knownEnvs adds the environment variables of the given EnvVar and their nested EnvVar to known, and the environment
+// variables of the collections to collections.
+func (v *Viewer) knownEnvs(envs []*EnvVar, known map[string]bool, collections *[]string) {
+	for _, env := range envs {
+		if env.collectionEnv != "" {
+			known[env.collectionEnv] = true
+			*collections = append(*collections, env.collectionEnv)
+		}
+
+		if nested, ok := env.Value.(map[string]*EnvVar); ok && env.isStruct {
+			for _, nestedEnv := range nested {
+				v.knownEnvs([]*EnvVar{nestedEnv}, known, collections)
+			}
+
+			continue
+		}
+
+		known[env.Env] = true
+	}
+}
+
+// hasCollectionPrefix reports whether the given environment variable sets an element of one of the given
+// collections.
+func hasCollectionPrefix(name string, collections []string, naming NamingStrategy) bool {
+	for _, collection := range collections {
+		if strings.HasPrefix(name, collection+naming.Separator()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suggestions returns the known environment variables closest to the given one, if they are close enough to be a
+// likely misspelling.
+func suggestions(name string, known map[string]bool) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	maxDistance := len(name) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var candidates []candidate
+
+	for knownName := range known {
+		if distance := editDistance(name, knownName); distance <= maxDistance {
+			candidates = append(candidates, candidate{name: knownName, distance: distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+
+		return candidates[i].name < candidates[j].name
+	})
+
+	if len(candidates) > maxSuggestions {
+		candidates = candidates[:maxSuggestions]
+	}
+
+	var names []string
+
+	for _, c := range candidates {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+// editDistance returns the Damerau-Levenshtein distance between the given strings, counting the transpositions of
+// adjacent characters, e.g. 'PROT' for 'PORT', as a single edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
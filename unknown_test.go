@@ -0,0 +1,146 @@
+package structviewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnknownEnvs(t *testing.T) {
+	viewer, err := New(&Config{Object: testProvenanceConfig{
+		Tags:    []string{"a"},
+		Headers: map[string]string{"X-Env": "prod"},
+	}}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		testName        string
+		givenEnviron    []string
+		expectedUnknown []UnknownEnv
+	}{
+		{
+			testName:        "known",
+			givenEnviron:    []string{"APP_LISTENPORT=8080", "APP_STORAGE_ADDR=redis:6379", "APP_DEBUG=true"},
+			expectedUnknown: []UnknownEnv{},
+		},
+		{
+			testName:        "collections",
+			givenEnviron:    []string{"APP_TAGS=a,b", "APP_TAGS_1=b", "APP_HEADERS_X-TRACE=1", "APP_HEADERS=a:b"},
+			expectedUnknown: []UnknownEnv{},
+		},
+		{
+			testName:        "other prefixes",
+			givenEnviron:    []string{"PATH=/bin", "OTHER_LISTENPROT=8080"},
+			expectedUnknown: []UnknownEnv{},
+		},
+		{
+			testName:     "typos",
+			givenEnviron: []string{"APP_LISTENPROT=8080", "APP_STORAGE_ADR=redis", "APP_UNRELATED_VARIABLE=1"},
+			expectedUnknown: []UnknownEnv{
+				{Name: "APP_LISTENPROT", Suggestions: []string{"APP_LISTENPORT"}},
+				{Name: "APP_STORAGE_ADR", Suggestions: []string{"APP_STORAGE_ADDR"}},
+				{Name: "APP_UNRELATED_VARIABLE"},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			assert.Equal(t, tc.expectedUnknown, viewer.UnknownEnvs(tc.givenEnviron))
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tcs := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "PORT", b: "PORT", expected: 0},
+		{a: "PROT", b: "PORT", expected: 1},
+		{a: "ADR", b: "ADDR", expected: 1},
+		{a: "", b: "ADDR", expected: 4},
+		{a: "KITTEN", b: "SITTING", expected: 3},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.a+"-"+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.expected, editDistance(tc.a, tc.b))
+		})
+	}
+}
+
+func TestUnknownEnvsHandler(t *testing.T) {
+	viewer, err := New(&Config{
+		Object:  testProvenanceConfig{},
+		Environ: map[string]string{"APP_LISTENPROT": "8080", "APP_DEBUG": "true"},
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	rw := httptest.NewRecorder()
+	viewer.UnknownEnvsHandler(rw, httptest.NewRequest(http.MethodGet, "/unknown-envs", nil))
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	var unknown []UnknownEnv
+	assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &unknown))
+	assert.Equal(t, []UnknownEnv{{Name: "APP_LISTENPROT", Suggestions: []string{"APP_LISTENPORT"}}}, unknown)
+}
+
+func TestUnknownEnvsHandlerAudience(t *testing.T) {
+	config := struct {
+		ListenPort int `json:"listen_port"`
+		Vault      struct {
+			Token string `json:"token"`
+		} `json:"vault" structviewer:"audience=admin"`
+	}{}
+
+	viewer, err := New(&Config{
+		Object:       config,
+		Environ:      map[string]string{"APP_VAULT_TOKNE": "token"},
+		AudienceFunc: func(r *http.Request) string { return r.Header.Get("X-Audience") },
+	}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	tcs := []struct {
+		audience            string
+		expectedSuggestions []string
+	}{
+		{audience: PublicAudience, expectedSuggestions: nil},
+		{audience: AdminAudience, expectedSuggestions: []string{"APP_VAULT_TOKEN"}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.audience, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/unknown-envs", nil)
+			req.Header.Set("X-Audience", tc.audience)
+
+			rw := httptest.NewRecorder()
+			viewer.UnknownEnvsHandler(rw, req)
+
+			assert.Equal(t, http.StatusOK, rw.Code)
+
+			var unknown []UnknownEnv
+			assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &unknown))
+			assert.Equal(t, []UnknownEnv{{Name: "APP_VAULT_TOKNE", Suggestions: tc.expectedSuggestions}}, unknown)
+		})
+	}
+}
+
+func TestWarnUnknownEnvs(t *testing.T) {
+	viewer, err := New(&Config{Object: testProvenanceConfig{}}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	var buf bytes.Buffer
+
+	unknown := viewer.WarnUnknownEnvs(slog.New(slog.NewTextHandler(&buf, nil)), []string{"APP_LISTENPROT=8080"})
+
+	assert.Len(t, unknown, 1)
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), `msg="unknown environment variable" env=APP_LISTENPROT suggestions=[APP_LISTENPORT]`)
+}
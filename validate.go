@@ -0,0 +1,301 @@
+package structviewer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// validateTag is the tag of the validation constraints of the fields, as read by
+// github.com/go-playground/validator.
+const validateTag = "validate"
+
+// Validation constraints supported in the validate tag of the fields. Other constraints are ignored.
+const (
+	// RequiredConstraint requires the value to be set: non-nil for pointers, slices and maps, non-zero otherwise.
+	RequiredConstraint = "required"
+	// OmitEmptyConstraint skips the other constraints of unset values.
+	OmitEmptyConstraint = "omitempty"
+	// MinConstraint is the minimum of numbers, or the minimum length of strings, slices and maps.
+	MinConstraint = "min"
+	// MaxConstraint is the maximum of numbers, or the maximum length of strings, slices and maps.
+	MaxConstraint = "max"
+	// LenConstraint is the exact value of numbers, or the exact length of strings, slices and maps.
+	LenConstraint = "len"
+	// OneOfConstraint is the space-separated list of the allowed values, e.g. 'oneof=debug info warn'.
+	OneOfConstraint = "oneof"
+	// URLConstraint requires the value to be an absolute URL.
+	URLConstraint = "url"
+	// HostnamePortConstraint requires the value to be a 'host:port' pair, e.g. 'localhost:8080'.
+	HostnamePortConstraint = "hostname_port"
+)
+
+// hostnameRegexp matches the hostnames as defined by RFC 1123.
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9][a-zA-Z0-9-]{0,62})(\.[a-zA-Z0-9][a-zA-Z0-9-]{0,62})*\.?$`)
+
+// Constraint is a validation constraint of a field, parsed from its `validate:"..."` tag.
+type Constraint struct {
+	// Name is the name of the constraint, e.g. 'min'.
+	Name string `json:"name"`
+	// Param is the parameter of the constraint, e.g. '1' for 'min=1'.
+	Param string `json:"param,omitempty"`
+}
+
+// String returns the constraint as it is written in the validate tag, e.g. 'min=1'.
+func (c Constraint) String() string {
+	if c.Param == "" {
+		return c.Name
+	}
+
+	return c.Name + "=" + c.Param
+}
+
+// Violation is a field violating one of its validation constraints.
+type Violation struct {
+	// Path is the JSON notation of the field, e.g. 'storage.pool_size'.
+	Path string `json:"path"`
+	// Env is the environment variable notation of the field, e.g. 'PREFIX_STORAGE_POOLSIZE'.
+	Env string `json:"env"`
+	// Constraint is the violated constraint, e.g. 'max=65535'.
+	Constraint string `json:"constraint"`
+	// Message describes the violation, without revealing the value of the field.
+	Message string `json:"message"`
+}
+
+// parseConstraints returns the supported constraints of the given validate tag.
+func parseConstraints(tag string) []Constraint {
+	var constraints []Constraint
+
+	for _, option := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+		switch name {
+		case RequiredConstraint, OmitEmptyConstraint, MinConstraint, MaxConstraint, LenConstraint, OneOfConstraint,
+			URLConstraint, HostnamePortConstraint:
+			constraints = append(constraints, Constraint{Name: name, Param: param})
+		default:
+		}
+	}
+
+	return constraints
+}
+
+// Validate checks the unredacted configuration against the constraints of its fields and returns the violations,
+// with the JSON and the environment variable notations of the fields. The validation constraints are parsed from the
+// `validate:"..."` tag of the fields, supporting the following subset of github.com/go-playground/validator:
+// required, omitempty, min, max, len, oneof, url and hostname_port. The fields of the structs held by slices and maps
+// are validated if the naming strategy expands the collections.
+func (v *Viewer) Validate() []Violation {
+	violations := []Violation{}
+	v.validateStruct(indirect(reflect.ValueOf(v.original)), "", v.prefix, map[pointerKey]bool{}, &violations)
+
+	return violations
+}
+
+// validateStruct adds the violations of the fields of the given struct to violations. path and prefix are the JSON
+// path and the environment variable prefix of the struct. visited holds the addresses of the structs being validated,
+// so that pointer cycles are not validated endlessly.
+func (v *Viewer) validateStruct(s reflect.Value,
+	path, prefix string,
+	visited map[pointerKey]bool,
+	violations *[]Violation,
+) {
+	if s.CanAddr() {
+		key := pointerKey{addr: s.UnsafeAddr(), t: s.Type()}
+		visited[key] = true
+
+		defer delete(visited, key)
+	}
+
+	for _, field := range v.structFields(s.Type()) {
+		fieldPath := joinGoPath(path, v.naming.FieldPath(field.Field))
+		fieldEnv := prefix + v.naming.FieldEnv(field.Field)
+		fieldValue := fieldByIndex(s, field.index)
+
+		for _, constraint := range parseConstraints(field.Tag.Get(validateTag)) {
+			if message, ok := checkConstraint(fieldValue, constraint); !ok {
+				*violations = append(*violations, Violation{
+					Path:       fieldPath,
+					Env:        fieldEnv,
+					Constraint: constraint.String(),
+					Message:    message,
+				})
+
+				if constraint.Name == RequiredConstraint {
+					break
+				}
+			}
+
+			if constraint.Name == OmitEmptyConstraint && !hasValue(fieldValue) {
+				break
+			}
+		}
+
+		v.validateValue(fieldValue, fieldPath, fieldEnv, visited, violations)
+	}
+}
+
+// validateValue adds the violations of the fields of the given value, if it is a struct or a collection of structs,
+// to violations. The pointers to the structs being validated are skipped.
+func (v *Viewer) validateValue(value reflect.Value,
+	path, env string,
+	visited map[pointerKey]bool,
+	violations *[]Violation,
+) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+
+		if value.Kind() == reflect.Ptr && visited[pointerKey{addr: value.Pointer(), t: value.Type().Elem()}] {
+			return
+		}
+
+		value = value.Elem()
+	}
+
+	if isFormatted(value) {
+		return
+	}
+
+	separator := v.naming.Separator()
+
+	switch {
+	case value.Kind() == reflect.Struct:
+		v.validateStruct(value, path, env+separator, visited, violations)
+	case value.Kind() == reflect.Map && v.naming.ExpandCollections():
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%v", keys[i]) < fmt.Sprintf("%v", keys[j])
+		})
+
+		for _, key := range keys {
+			keyStr := fmt.Sprintf("%v", key)
+			v.validateValue(value.MapIndex(key), joinGoPath(path, v.naming.MapKeyPath(keyStr)),
+				env+separator+v.naming.MapKeyEnv(keyStr), visited, violations)
+		}
+	case isSliceOrArray(value) && v.naming.ExpandCollections():
+		for i := 0; i < value.Len(); i++ {
+			v.validateValue(value.Index(i), joinGoPath(path, v.naming.IndexPath(i)),
+				env+separator+v.naming.IndexEnv(i), visited, violations)
+		}
+	default:
+	}
+}
+
+// checkConstraint reports whether the given value satisfies the given constraint, along with the description of the
+// violation if it does not. Unset values only violate the required constraint.
+func checkConstraint(value reflect.Value, constraint Constraint) (string, bool) {
+	if constraint.Name == RequiredConstraint {
+		return "is required", hasValue(value)
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", true
+		}
+
+		value = value.Elem()
+	}
+
+	switch constraint.Name {
+	case MinConstraint:
+		return checkBound(value, constraint.Param, "at least", func(n, bound float64) bool { return n >= bound })
+	case MaxConstraint:
+		return checkBound(value, constraint.Param, "at most", func(n, bound float64) bool { return n <= bound })
+	case LenConstraint:
+		return checkBound(value, constraint.Param, "exactly", func(n, bound float64) bool { return n == bound })
+	case OneOfConstraint:
+		allowed := strings.Fields(constraint.Param)
+		for _, a := range allowed {
+			if stringValue(value) == a {
+				return "", true
+			}
+		}
+
+		return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")), false
+	case URLConstraint:
+		u, err := url.Parse(stringValue(value))
+
+		return "must be an absolute URL", err == nil && u.Scheme != ""
+	case HostnamePortConstraint:
+		return "must be a host:port pair", isHostnamePort(stringValue(value))
+	default:
+		return "", true
+	}
+}
+
+// checkBound reports whether the size of the given value, i.e. the value of numbers and the length of strings,
+// slices and maps, satisfies the given comparison with the given bound. The bounds of durations can be durations,
+// e.g. 'min=1s'.
+func checkBound(value reflect.Value, param, relation string, compare func(n, bound float64) bool) (string, bool) {
+	var n float64
+
+	unit := ""
+
+	switch kind := value.Kind(); {
+	case kind == reflect.String:
+		n, unit = float64(utf8.RuneCountInString(value.String())), " characters"
+	case kind == reflect.Slice, kind == reflect.Array, kind == reflect.Map:
+		n, unit = float64(value.Len()), " elements"
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		n = float64(value.Int())
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		n = float64(value.Uint())
+	case kind == reflect.Float32, kind == reflect.Float64:
+		n = value.Float()
+	default:
+		return fmt.Sprintf("cannot be compared to %s", param), false
+	}
+
+	bound, err := strconv.ParseFloat(param, 64)
+
+	if value.Type() == durationType {
+		if d, durationErr := time.ParseDuration(param); durationErr == nil {
+			bound, err = float64(d), nil
+		}
+	}
+
+	if err != nil {
+		return fmt.Sprintf("has an invalid bound %s", strconv.Quote(param)), false
+	}
+
+	if unit == "" {
+		return fmt.Sprintf("must be %s %s", relation, param), compare(n, bound)
+	}
+
+	return fmt.Sprintf("must have %s %s%s", relation, param, unit), compare(n, bound)
+}
+
+// hasValue reports whether the given value is set: non-nil for pointers, interfaces, slices and maps, non-zero
+// otherwise.
+func hasValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+		return !value.IsNil()
+	default:
+		return value.IsValid() && !value.IsZero()
+	}
+}
+
+// isHostnamePort reports whether the given string is a 'host:port' pair, whose host is a hostname or an IP address,
+// if any, and whose port is between 1 and 65535.
+func isHostnamePort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return false
+	}
+
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return false
+	}
+
+	return host == "" || net.ParseIP(host) != nil || hostnameRegexp.MatchString(host)
+}
@@ -0,0 +1,195 @@
+package structviewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testValidatedConfig struct {
+	ListenPort int                     `json:"listen_port" validate:"required,min=1,max=65535"`
+	LogLevel   string                  `json:"log_level" validate:"oneof=debug info warn"`
+	Secret     string                  `json:"secret" validate:"len=8" structviewer:"obfuscate"`
+	Timeout    time.Duration           `json:"timeout" validate:"min=1s"`
+	Tags       []string                `json:"tags" validate:"omitempty,max=2,dive"`
+	Storage    *testValidatedStorage   `json:"storage" validate:"required"`
+	Upstreams  []testValidatedUpstream `json:"upstreams"`
+}
+
+type testValidatedStorage struct {
+	Addr string `json:"addr" validate:"hostname_port"`
+}
+
+type testValidatedUpstream struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+func TestParseConstraints(t *testing.T) {
+	tcs := []struct {
+		givenTag string
+		expected []Constraint
+	}{
+		{givenTag: "", expected: nil},
+		{
+			givenTag: "required,min=1,max=65535",
+			expected: []Constraint{{Name: "required"}, {Name: "min", Param: "1"}, {Name: "max", Param: "65535"}},
+		},
+		{
+			givenTag: "omitempty,dive,email,oneof=a b",
+			expected: []Constraint{{Name: "omitempty"}, {Name: "oneof", Param: "a b"}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.givenTag, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseConstraints(tc.givenTag))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tcs := []struct {
+		testName    string
+		givenConfig testValidatedConfig
+		expected    []Violation
+	}{
+		{
+			testName: "valid",
+			givenConfig: testValidatedConfig{
+				ListenPort: 8080,
+				LogLevel:   "info",
+				Secret:     "12345678",
+				Timeout:    time.Minute,
+				Storage:    &testValidatedStorage{Addr: "redis:6379"},
+				Upstreams:  []testValidatedUpstream{{URL: "http://upstream"}},
+			},
+			expected: []Violation{},
+		},
+		{
+			testName: "invalid",
+			givenConfig: testValidatedConfig{
+				ListenPort: 70000,
+				LogLevel:   "trace",
+				Secret:     "secret",
+				Timeout:    time.Millisecond,
+				Tags:       []string{"a", "b", "c"},
+				Storage:    &testValidatedStorage{Addr: "redis"},
+				Upstreams:  []testValidatedUpstream{{URL: "http://upstream"}, {URL: "upstream"}, {}},
+			},
+			expected: []Violation{
+				{Path: "listen_port", Env: "APP_LISTENPORT", Constraint: "max=65535", Message: "must be at most 65535"},
+				{
+					Path: "log_level", Env: "APP_LOGLEVEL", Constraint: "oneof=debug info warn",
+					Message: "must be one of debug, info, warn",
+				},
+				{Path: "secret", Env: "APP_SECRET", Constraint: "len=8", Message: "must have exactly 8 characters"},
+				{Path: "timeout", Env: "APP_TIMEOUT", Constraint: "min=1s", Message: "must be at least 1s"},
+				{Path: "tags", Env: "APP_TAGS", Constraint: "max=2", Message: "must have at most 2 elements"},
+				{
+					Path: "storage.addr", Env: "APP_STORAGE_ADDR", Constraint: "hostname_port",
+					Message: "must be a host:port pair",
+				},
+				{Path: "upstreams.1.url", Env: "APP_UPSTREAMS_1_URL", Constraint: "url", Message: "must be an absolute URL"},
+				{Path: "upstreams.2.url", Env: "APP_UPSTREAMS_2_URL", Constraint: "required", Message: "is required"},
+			},
+		},
+		{
+			testName:    "missing",
+			givenConfig: testValidatedConfig{LogLevel: "debug", Secret: "12345678", Timeout: time.Second},
+			expected: []Violation{
+				{Path: "listen_port", Env: "APP_LISTENPORT", Constraint: "required", Message: "is required"},
+				{Path: "storage", Env: "APP_STORAGE", Constraint: "required", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{Object: tc.givenConfig}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			assert.Equal(t, tc.expected, viewer.Validate())
+		})
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	viewer, err := New(&Config{Object: testValidatedConfig{}}, "APP_")
+	assert.NoError(t, err, "failed to instantiate viewer")
+
+	assert.Equal(t, []Constraint{{Name: "required"}, {Name: "min", Param: "1"}, {Name: "max", Param: "65535"}},
+		viewer.EnvNotation("listen_port").Constraints)
+	assert.Empty(t, viewer.EnvNotation("upstreams").Constraints)
+}
+
+func TestIsHostnamePort(t *testing.T) {
+	tcs := map[string]bool{
+		"localhost:8080":  true,
+		"127.0.0.1:6379":  true,
+		"[::1]:443":       true,
+		":8080":           true,
+		"redis":           false,
+		"redis:0":         false,
+		"redis:65536":     false,
+		"red_is:6379":     false,
+		"example.com:443": true,
+	}
+
+	for given, expected := range tcs {
+		t.Run(given, func(t *testing.T) {
+			assert.Equal(t, expected, isHostnamePort(given))
+		})
+	}
+}
+
+func TestValidateHandler(t *testing.T) {
+	tcs := []struct {
+		testName       string
+		givenConfig    testValidatedConfig
+		expectedStatus int
+		expectedLen    int
+	}{
+		{
+			testName: "valid",
+			givenConfig: testValidatedConfig{
+				ListenPort: 8080, LogLevel: "info", Secret: "12345678", Timeout: time.Second,
+				Storage: &testValidatedStorage{Addr: "redis:6379"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "invalid",
+			givenConfig:    testValidatedConfig{LogLevel: "info", Secret: "12345678", Timeout: time.Second},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedLen:    2,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			viewer, err := New(&Config{
+				Object:       tc.givenConfig,
+				AudienceFunc: func(r *http.Request) string { return r.Header.Get("X-Audience") },
+			}, "APP_")
+			assert.NoError(t, err, "failed to instantiate viewer")
+
+			for _, audience := range []string{"", AdminAudience} {
+				req := httptest.NewRequest(http.MethodGet, "/validate", nil)
+				req.Header.Set("X-Audience", audience)
+
+				rw := httptest.NewRecorder()
+				viewer.ValidateHandler(rw, req)
+
+				assert.Equal(t, tc.expectedStatus, rw.Code, audience)
+
+				var violations []Violation
+				assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), &violations))
+				assert.Len(t, violations, tc.expectedLen, audience)
+			}
+		})
+	}
+}